@@ -0,0 +1,206 @@
+// Package analyzer checks an image with several boxes at once, and
+// combines their results into a single Report.
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/pkg/errors"
+)
+
+// Analyzer checks images with every box that has a client.
+type Analyzer struct {
+	// Facebox is the client used to find faces. If nil, faces
+	// are not found.
+	Facebox *facebox.Client
+	// Tagbox is the client used to tag images. If nil, images
+	// are not tagged.
+	Tagbox *tagbox.Client
+	// Nudebox is the client used to detect nudity. If nil, nudity
+	// is not detected.
+	Nudebox *nudebox.Client
+	// Objectbox is the client used to find objects. If nil, objects
+	// are not found.
+	Objectbox *objectbox.Client
+}
+
+// Report is the combined results of the boxes.
+// Results are empty for boxes that were not used or failed; Boxes
+// has the boxes that were used.
+type Report struct {
+	// Faces are the faces found by Facebox.
+	Faces []Face `json:"faces,omitempty"`
+	// Tags are the tags from Tagbox.
+	Tags []Tag `json:"tags,omitempty"`
+	// CustomTags are the custom tags from Tagbox.
+	CustomTags []Tag `json:"custom_tags,omitempty"`
+	// Nude is the nudity probability from Nudebox.
+	Nude *float64 `json:"nude,omitempty"`
+	// Objects are the objects found by Objectbox, by detector.
+	Objects []objectbox.CheckDetectorResponse `json:"objects,omitempty"`
+	// Boxes describe how each box that was used got on, by box name.
+	Boxes map[string]Box `json:"boxes"`
+}
+
+// Box describes how a box got on checking the image.
+type Box struct {
+	// Latency is how long the box took.
+	Latency time.Duration `json:"-"`
+	// LatencyMS is Latency in milliseconds.
+	LatencyMS float64 `json:"latency_ms"`
+	// Error is why the box failed, or empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Face is a face found by Facebox.
+type Face struct {
+	Rect       Rect    `json:"rect"`
+	ID         string  `json:"id,omitempty"`
+	Name       string  `json:"name,omitempty"`
+	Matched    bool    `json:"matched"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Rect is the coordinates of a face within the image.
+type Rect struct {
+	Top    int `json:"top"`
+	Left   int `json:"left"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Tag is a tag from Tagbox.
+type Tag struct {
+	Tag        string  `json:"tag"`
+	Confidence float64 `json:"confidence"`
+	ID         string  `json:"id,omitempty"`
+}
+
+// Failed checks whether any of the boxes failed.
+func (r *Report) Failed() bool {
+	for _, box := range r.Boxes {
+		if box.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Check checks the image with the boxes concurrently.
+// Check only returns an error if the image could not be read or no
+// boxes are set; errors from boxes are reported in Report.Boxes.
+func (a *Analyzer) Check(image io.Reader) (*Report, error) {
+	if a.Facebox == nil && a.Tagbox == nil && a.Nudebox == nil && a.Objectbox == nil {
+		return nil, errors.New("analyzer: no boxes")
+	}
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	report := &Report{
+		Boxes: make(map[string]Box),
+	}
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+	)
+	run := func(name string, check func(r io.Reader) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := check(bytes.NewReader(data))
+			latency := time.Since(start)
+			box := Box{
+				Latency:   latency,
+				LatencyMS: float64(latency) / float64(time.Millisecond),
+			}
+			if err != nil {
+				box.Error = err.Error()
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			report.Boxes[name] = box
+		}()
+	}
+	if a.Facebox != nil {
+		run("facebox", func(r io.Reader) error {
+			faces, err := a.Facebox.Check(r)
+			if err != nil {
+				return err
+			}
+			report.Faces = make([]Face, len(faces))
+			for i, face := range faces {
+				report.Faces[i] = Face{
+					Rect: Rect{
+						Top:    face.Rect.Top,
+						Left:   face.Rect.Left,
+						Width:  face.Rect.Width,
+						Height: face.Rect.Height,
+					},
+					ID:         face.ID,
+					Name:       face.Name,
+					Matched:    face.Matched,
+					Confidence: face.Confidence,
+				}
+			}
+			return nil
+		})
+	}
+	if a.Tagbox != nil {
+		run("tagbox", func(r io.Reader) error {
+			response, err := a.Tagbox.Check(r)
+			if err != nil {
+				return err
+			}
+			report.Tags = tags(response.Tags)
+			report.CustomTags = tags(response.CustomTags)
+			return nil
+		})
+	}
+	if a.Nudebox != nil {
+		run("nudebox", func(r io.Reader) error {
+			nude, err := a.Nudebox.Check(r)
+			if err != nil {
+				return err
+			}
+			report.Nude = &nude
+			return nil
+		})
+	}
+	if a.Objectbox != nil {
+		run("objectbox", func(r io.Reader) error {
+			response, err := a.Objectbox.Check(r)
+			if err != nil {
+				return err
+			}
+			report.Objects = response.Detectors
+			return nil
+		})
+	}
+	wg.Wait()
+	return report, nil
+}
+
+func tags(tags []tagbox.Tag) []Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	converted := make([]Tag, len(tags))
+	for i, tag := range tags {
+		converted[i] = Tag{
+			Tag:        tag.Tag,
+			Confidence: tag.Confidence,
+			ID:         tag.ID,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,144 @@
+package analyzer_test
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/machinebox/sdk-go/analyzer"
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	var (
+		lock    sync.Mutex
+		uploads = make(map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		lock.Lock()
+		uploads[r.URL.Path] = string(b)
+		lock.Unlock()
+		switch r.URL.Path {
+		case "/facebox/check":
+			io.WriteString(w, `{
+				"success": true,
+				"faces": [
+					{
+						"rect": { "top": 1, "left": 2, "width": 3, "height": 4 },
+						"id": "file1.jpg",
+						"name": "John Lennon",
+						"matched": true,
+						"confidence": 0.8
+					}
+				]
+			}`)
+		case "/tagbox/check":
+			io.WriteString(w, `{
+				"success": true,
+				"tags": [{"tag": "music", "confidence": 0.9}],
+				"custom_tags": [{"tag": "beatles", "confidence": 0.7, "id": "abbey.jpg"}]
+			}`)
+		case "/nudebox/check":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"success": false, "error": "out of memory"}`)
+		case "/objectbox/check":
+			io.WriteString(w, `{
+				"success": true,
+				"detectors": [
+					{
+						"id": "guitars",
+						"objects": [{ "rect": { "top": 5, "left": 6, "width": 7, "height": 8 }, "score": 0.6 }]
+					}
+				]
+			}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	a := &analyzer.Analyzer{
+		Facebox:   facebox.New(srv.URL),
+		Tagbox:    tagbox.New(srv.URL),
+		Nudebox:   nudebox.New(srv.URL),
+		Objectbox: objectbox.New(srv.URL),
+	}
+	report, err := a.Check(strings.NewReader(`(pretend this is image data)`))
+	is.NoErr(err)
+	is.Equal(len(uploads), 4)
+	for _, upload := range uploads {
+		is.Equal(upload, `(pretend this is image data)`)
+	}
+
+	is.Equal(report.Faces, []analyzer.Face{{
+		Rect:       analyzer.Rect{Top: 1, Left: 2, Width: 3, Height: 4},
+		ID:         "file1.jpg",
+		Name:       "John Lennon",
+		Matched:    true,
+		Confidence: 0.8,
+	}})
+	is.Equal(report.Tags, []analyzer.Tag{{Tag: "music", Confidence: 0.9}})
+	is.Equal(report.CustomTags, []analyzer.Tag{{Tag: "beatles", Confidence: 0.7, ID: "abbey.jpg"}})
+	is.Equal(report.Nude, nil)
+	is.Equal(len(report.Objects), 1)
+	is.Equal(report.Objects[0].ID, "guitars")
+	is.Equal(report.Objects[0].Objects[0].Rect.Width, 7)
+
+	is.Equal(len(report.Boxes), 4)
+	is.Equal(report.Boxes["facebox"].Error, "")
+	is.Equal(report.Boxes["nudebox"].Error, "nudebox: out of memory")
+	is.True(report.Failed())
+
+	b, err := json.Marshal(report)
+	is.NoErr(err)
+	var decoded map[string]interface{}
+	is.NoErr(json.Unmarshal(b, &decoded))
+	is.Equal(decoded["faces"].([]interface{})[0].(map[string]interface{})["name"], "John Lennon")
+	is.Equal(decoded["custom_tags"].([]interface{})[0].(map[string]interface{})["tag"], "beatles")
+	_, ok := decoded["nude"]
+	is.Equal(ok, false)
+	boxes := decoded["boxes"].(map[string]interface{})
+	nude := boxes["nudebox"].(map[string]interface{})
+	is.Equal(nude["error"], "nudebox: out of memory")
+	_, ok = nude["latency_ms"]
+	is.True(ok)
+}
+
+func TestCheckSomeBoxes(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/nudebox/check")
+		io.WriteString(w, `{"success": true, "nude": 0.25}`)
+	}))
+	defer srv.Close()
+	a := &analyzer.Analyzer{
+		Nudebox: nudebox.New(srv.URL),
+	}
+	report, err := a.Check(strings.NewReader(`(pretend this is image data)`))
+	is.NoErr(err)
+	is.Equal(*report.Nude, 0.25)
+	is.Equal(len(report.Boxes), 1)
+	is.Equal(report.Failed(), false)
+	is.Equal(report.Faces, nil)
+}
+
+func TestCheckNoBoxes(t *testing.T) {
+	is := is.New(t)
+	a := &analyzer.Analyzer{}
+	_, err := a.Check(strings.NewReader(`(pretend this is image data)`))
+	is.Equal(err.Error(), "analyzer: no boxes")
+}
@@ -0,0 +1,139 @@
+// Package annotation converts Objectbox and Facebox results into the
+// COCO and Pascal VOC annotation formats, so they can be used to
+// bootstrap labelling datasets, and reads corrected annotations back
+// so box results can be evaluated against them.
+//
+// Objectbox objects are annotated with the ID of the detector that
+// found them as the category.
+package annotation
+
+import (
+	"sort"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/objectbox"
+)
+
+// Dataset is a set of annotated images.
+type Dataset struct {
+	// Images are the annotated images.
+	Images []Image
+	// Categories are the names of the categories, in order.
+	// Categories used by annotations that are not in the list are
+	// added when the dataset is written.
+	Categories []string
+}
+
+// Image is an image and its annotations.
+type Image struct {
+	// File is the file name of the image.
+	File string
+	// Width and Height are the dimensions of the image in pixels.
+	Width, Height int
+	// Annotations are the things in the image.
+	Annotations []Annotation
+}
+
+// Annotation is a labelled area of an image.
+type Annotation struct {
+	// Category is the name of the category.
+	Category string
+	// Box is where the thing is in the image.
+	Box Box
+	// Score is the confidence of the box, or zero for
+	// annotations made by people.
+	Score float64
+}
+
+// Box is a rectangle in pixels.
+type Box struct {
+	Left, Top     int
+	Width, Height int
+}
+
+// Add adds an image to the dataset.
+func (d *Dataset) Add(file string, width, height int, annotations ...Annotation) {
+	d.Images = append(d.Images, Image{
+		File:        file,
+		Width:       width,
+		Height:      height,
+		Annotations: annotations,
+	})
+}
+
+// Image gets the image with the file name.
+func (d *Dataset) Image(file string) (Image, bool) {
+	for _, image := range d.Images {
+		if image.File == file {
+			return image, true
+		}
+	}
+	return Image{}, false
+}
+
+// categories gets the categories followed by any others used by the
+// annotations, sorted by name.
+func (d *Dataset) categories() []string {
+	categories := append([]string(nil), d.Categories...)
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		seen[category] = true
+	}
+	var extra []string
+	for _, image := range d.Images {
+		for _, annotation := range image.Annotations {
+			if seen[annotation.Category] {
+				continue
+			}
+			seen[annotation.Category] = true
+			extra = append(extra, annotation.Category)
+		}
+	}
+	sort.Strings(extra)
+	return append(categories, extra...)
+}
+
+// ObjectAnnotations makes annotations from the objects in the response.
+// The category of each annotation is the ID of its detector.
+func ObjectAnnotations(response objectbox.CheckResponse) []Annotation {
+	var annotations []Annotation
+	for _, detector := range response.Detectors {
+		for _, object := range detector.Objects {
+			annotations = append(annotations, Annotation{
+				Category: detector.ID,
+				Box: Box{
+					Left:   object.Rect.Left,
+					Top:    object.Rect.Top,
+					Width:  object.Rect.Width,
+					Height: object.Rect.Height,
+				},
+				Score: object.Score,
+			})
+		}
+	}
+	return annotations
+}
+
+// FaceAnnotations makes annotations from faces. The category of each
+// annotation is "face", unless names is true and the face was
+// recognized, in which case it is the name of the person.
+func FaceAnnotations(faces []facebox.Face, names bool) []Annotation {
+	var annotations []Annotation
+	for _, face := range faces {
+		category := "face"
+		if names && face.Matched && face.Name != "" {
+			category = face.Name
+		}
+		annotations = append(annotations, Annotation{
+			Category: category,
+			Box: Box{
+				Left:   face.Rect.Left,
+				Top:    face.Rect.Top,
+				Width:  face.Rect.Width,
+				Height: face.Rect.Height,
+			},
+			Score: face.Confidence,
+		})
+	}
+	return annotations
+}
@@ -0,0 +1,126 @@
+package annotation
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+type cocoFile struct {
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID         int        `json:"id"`
+	ImageID    int        `json:"image_id"`
+	CategoryID int        `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"`
+	Area       float64    `json:"area"`
+	IsCrowd    int        `json:"iscrowd"`
+	Score      float64    `json:"score,omitempty"`
+}
+
+type cocoCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// WriteCOCO writes the dataset as COCO object detection JSON.
+// Images, annotations and categories are numbered from 1, in order.
+// Scores are included for annotations that have them.
+func WriteCOCO(w io.Writer, d Dataset) error {
+	file := cocoFile{
+		Images:      []cocoImage{},
+		Annotations: []cocoAnnotation{},
+		Categories:  []cocoCategory{},
+	}
+	categoryIDs := make(map[string]int)
+	for i, category := range d.categories() {
+		categoryIDs[category] = i + 1
+		file.Categories = append(file.Categories, cocoCategory{ID: i + 1, Name: category})
+	}
+	for i, image := range d.Images {
+		file.Images = append(file.Images, cocoImage{
+			ID:       i + 1,
+			FileName: image.File,
+			Width:    image.Width,
+			Height:   image.Height,
+		})
+		for _, annotation := range image.Annotations {
+			box := annotation.Box
+			file.Annotations = append(file.Annotations, cocoAnnotation{
+				ID:         len(file.Annotations) + 1,
+				ImageID:    i + 1,
+				CategoryID: categoryIDs[annotation.Category],
+				BBox:       [4]float64{float64(box.Left), float64(box.Top), float64(box.Width), float64(box.Height)},
+				Area:       float64(box.Width * box.Height),
+				Score:      annotation.Score,
+			})
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(file)
+}
+
+// ReadCOCO reads a dataset from COCO object detection JSON.
+// Boxes are rounded to the nearest pixel.
+func ReadCOCO(r io.Reader) (*Dataset, error) {
+	var file cocoFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errors.Wrap(err, "decode coco")
+	}
+	d := &Dataset{}
+	categories := make(map[int]string)
+	for _, category := range file.Categories {
+		if _, ok := categories[category.ID]; ok {
+			return nil, errors.Errorf("duplicate category id %d", category.ID)
+		}
+		categories[category.ID] = category.Name
+		d.Categories = append(d.Categories, category.Name)
+	}
+	images := make(map[int]int)
+	for _, image := range file.Images {
+		if _, ok := images[image.ID]; ok {
+			return nil, errors.Errorf("duplicate image id %d", image.ID)
+		}
+		images[image.ID] = len(d.Images)
+		d.Add(image.FileName, image.Width, image.Height)
+	}
+	for _, annotation := range file.Annotations {
+		i, ok := images[annotation.ImageID]
+		if !ok {
+			return nil, errors.Errorf("annotation %d: unknown image id %d", annotation.ID, annotation.ImageID)
+		}
+		category, ok := categories[annotation.CategoryID]
+		if !ok {
+			return nil, errors.Errorf("annotation %d: unknown category id %d", annotation.ID, annotation.CategoryID)
+		}
+		d.Images[i].Annotations = append(d.Images[i].Annotations, Annotation{
+			Category: category,
+			Box: Box{
+				Left:   round(annotation.BBox[0]),
+				Top:    round(annotation.BBox[1]),
+				Width:  round(annotation.BBox[2]),
+				Height: round(annotation.BBox[3]),
+			},
+			Score: annotation.Score,
+		})
+	}
+	return d, nil
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
@@ -0,0 +1,97 @@
+package annotation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/annotation"
+	"github.com/matryer/is"
+)
+
+func testDataset() annotation.Dataset {
+	d := annotation.Dataset{
+		Categories: []string{"people"},
+	}
+	d.Add("street.jpg", 640, 480,
+		annotation.Annotation{Category: "people", Box: annotation.Box{Left: 10, Top: 20, Width: 30, Height: 40}, Score: 0.9},
+		annotation.Annotation{Category: "cars", Box: annotation.Box{Left: 100, Top: 200, Width: 300, Height: 100}},
+	)
+	d.Add("empty.jpg", 800, 600)
+	d.Add("car.jpg", 1024, 768,
+		annotation.Annotation{Category: "cars", Box: annotation.Box{Left: 0, Top: 0, Width: 1024, Height: 768}},
+	)
+	return d
+}
+
+func TestWriteCOCO(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	is.NoErr(annotation.WriteCOCO(&buf, testDataset()))
+	var file struct {
+		Images []struct {
+			ID       int    `json:"id"`
+			FileName string `json:"file_name"`
+			Width    int    `json:"width"`
+			Height   int    `json:"height"`
+		} `json:"images"`
+		Annotations []map[string]interface{} `json:"annotations"`
+		Categories  []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"categories"`
+	}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &file))
+	is.Equal(len(file.Images), 3)
+	is.Equal(file.Images[1].ID, 2)
+	is.Equal(file.Images[1].FileName, "empty.jpg")
+	is.Equal(file.Images[1].Width, 800)
+	is.Equal(len(file.Categories), 2)
+	is.Equal(file.Categories[0].Name, "people")
+	is.Equal(file.Categories[1].ID, 2)
+	is.Equal(file.Categories[1].Name, "cars")
+	is.Equal(len(file.Annotations), 3)
+	is.Equal(file.Annotations[0]["score"], 0.9)
+	is.Equal(file.Annotations[1]["category_id"], 2.0)
+	is.Equal(file.Annotations[1]["bbox"], []interface{}{100.0, 200.0, 300.0, 100.0})
+	is.Equal(file.Annotations[1]["area"], 30000.0)
+	_, ok := file.Annotations[1]["score"]
+	is.Equal(ok, false)
+	is.Equal(file.Annotations[2]["id"], 3.0)
+	is.Equal(file.Annotations[2]["image_id"], 3.0)
+}
+
+func TestReadCOCO(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	is.NoErr(annotation.WriteCOCO(&buf, testDataset()))
+	d, err := annotation.ReadCOCO(&buf)
+	is.NoErr(err)
+	expected := testDataset()
+	expected.Categories = []string{"people", "cars"}
+	is.Equal(*d, expected)
+}
+
+func TestReadCOCOErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := annotation.ReadCOCO(strings.NewReader(`{
+		"images": [{"id": 1, "file_name": "a.jpg"}],
+		"categories": [{"id": 1, "name": "cars"}],
+		"annotations": [{"id": 7, "image_id": 2, "category_id": 1, "bbox": [0, 0, 1, 1]}]
+	}`))
+	is.Equal(err.Error(), "annotation 7: unknown image id 2")
+	_, err = annotation.ReadCOCO(strings.NewReader(`{
+		"images": [{"id": 1, "file_name": "a.jpg"}],
+		"categories": [{"id": 1, "name": "cars"}],
+		"annotations": [{"id": 7, "image_id": 1, "category_id": 3, "bbox": [0.4, 0.6, 1, 1]}]
+	}`))
+	is.Equal(err.Error(), "annotation 7: unknown category id 3")
+	d, err := annotation.ReadCOCO(strings.NewReader(`{
+		"images": [{"id": 5, "file_name": "a.jpg"}],
+		"categories": [{"id": 3, "name": "cars"}],
+		"annotations": [{"id": 7, "image_id": 5, "category_id": 3, "bbox": [0.4, 0.6, 10.5, 9.2]}]
+	}`))
+	is.NoErr(err)
+	is.Equal(d.Images[0].Annotations[0].Box, annotation.Box{Left: 0, Top: 1, Width: 11, Height: 9})
+}
@@ -0,0 +1,68 @@
+package annotation_test
+
+import (
+	"testing"
+
+	"github.com/machinebox/sdk-go/annotation"
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func TestObjectAnnotations(t *testing.T) {
+	is := is.New(t)
+	annotations := annotation.ObjectAnnotations(objectbox.CheckResponse{
+		Detectors: []objectbox.CheckDetectorResponse{
+			{
+				ID: "cars",
+				Objects: []objectbox.Object{
+					{Rect: objectbox.Rect{Left: 10, Top: 20, Width: 30, Height: 40}, Score: 0.9},
+				},
+			},
+			{
+				ID: "people",
+				Objects: []objectbox.Object{
+					{Rect: objectbox.Rect{Left: 1, Top: 2, Width: 3, Height: 4}, Score: 0.5},
+				},
+			},
+		},
+	})
+	is.Equal(len(annotations), 2)
+	is.Equal(annotations[0], annotation.Annotation{
+		Category: "cars",
+		Box:      annotation.Box{Left: 10, Top: 20, Width: 30, Height: 40},
+		Score:    0.9,
+	})
+	is.Equal(annotations[1].Category, "people")
+}
+
+func TestFaceAnnotations(t *testing.T) {
+	is := is.New(t)
+	faces := []facebox.Face{
+		{Rect: facebox.Rect{Left: 10, Top: 20, Width: 30, Height: 40}, Matched: true, Name: "John Lennon", Confidence: 0.8},
+		{Rect: facebox.Rect{Left: 100, Top: 20, Width: 30, Height: 40}},
+	}
+	annotations := annotation.FaceAnnotations(faces, false)
+	is.Equal(len(annotations), 2)
+	is.Equal(annotations[0].Category, "face")
+	is.Equal(annotations[0].Box, annotation.Box{Left: 10, Top: 20, Width: 30, Height: 40})
+	is.Equal(annotations[0].Score, 0.8)
+	is.Equal(annotations[1].Category, "face")
+
+	annotations = annotation.FaceAnnotations(faces, true)
+	is.Equal(annotations[0].Category, "John Lennon")
+	is.Equal(annotations[1].Category, "face")
+}
+
+func TestDatasetImage(t *testing.T) {
+	is := is.New(t)
+	var d annotation.Dataset
+	d.Add("one.jpg", 640, 480)
+	d.Add("two.jpg", 800, 600, annotation.Annotation{Category: "cars"})
+	image, ok := d.Image("two.jpg")
+	is.True(ok)
+	is.Equal(image.Width, 800)
+	is.Equal(len(image.Annotations), 1)
+	_, ok = d.Image("three.jpg")
+	is.Equal(ok, false)
+}
@@ -0,0 +1,168 @@
+package annotation
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type vocAnnotation struct {
+	XMLName  xml.Name    `xml:"annotation"`
+	Filename string      `xml:"filename"`
+	Size     vocSize     `xml:"size"`
+	Objects  []vocObject `xml:"object"`
+}
+
+type vocSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+	Depth  int `xml:"depth"`
+}
+
+type vocObject struct {
+	Name      string `xml:"name"`
+	Pose      string `xml:"pose"`
+	Truncated int    `xml:"truncated"`
+	Difficult int    `xml:"difficult"`
+	BndBox    struct {
+		XMin float64 `xml:"xmin"`
+		YMin float64 `xml:"ymin"`
+		XMax float64 `xml:"xmax"`
+		YMax float64 `xml:"ymax"`
+	} `xml:"bndbox"`
+}
+
+// WriteVOC writes the annotations of the image as Pascal VOC XML.
+// VOC coordinates start at 1, so a box with Left 0 has an xmin of 1.
+// Scores are not written.
+func WriteVOC(w io.Writer, image Image) error {
+	annotation := vocAnnotation{
+		Filename: image.File,
+		Size: vocSize{
+			Width:  image.Width,
+			Height: image.Height,
+			Depth:  3,
+		},
+	}
+	for _, a := range image.Annotations {
+		object := vocObject{
+			Name: a.Category,
+			Pose: "Unspecified",
+		}
+		object.BndBox.XMin = float64(a.Box.Left + 1)
+		object.BndBox.YMin = float64(a.Box.Top + 1)
+		object.BndBox.XMax = float64(a.Box.Left + a.Box.Width)
+		object.BndBox.YMax = float64(a.Box.Top + a.Box.Height)
+		annotation.Objects = append(annotation.Objects, object)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(annotation); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadVOC reads the annotations of an image from Pascal VOC XML.
+// Boxes are rounded to the nearest pixel.
+func ReadVOC(r io.Reader) (*Image, error) {
+	var annotation vocAnnotation
+	if err := xml.NewDecoder(r).Decode(&annotation); err != nil {
+		return nil, errors.Wrap(err, "decode voc")
+	}
+	image := &Image{
+		File:   annotation.Filename,
+		Width:  annotation.Size.Width,
+		Height: annotation.Size.Height,
+	}
+	for _, object := range annotation.Objects {
+		box := object.BndBox
+		left, top := round(box.XMin)-1, round(box.YMin)-1
+		image.Annotations = append(image.Annotations, Annotation{
+			Category: object.Name,
+			Box: Box{
+				Left:   left,
+				Top:    top,
+				Width:  round(box.XMax) - left,
+				Height: round(box.YMax) - top,
+			},
+		})
+	}
+	return image, nil
+}
+
+// vocPath gets the path of the VOC file for the image in the directory,
+// which replaces the extension of the image with .xml.
+func vocPath(dir, file string) string {
+	base := filepath.Base(file)
+	return filepath.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+".xml")
+}
+
+// WriteVOCDir writes a Pascal VOC XML file for each image in the
+// dataset to the directory, named after the image.
+// Images in different folders with the same name would be written
+// to the same file, so WriteVOCDir returns an error before writing
+// anything.
+func WriteVOCDir(dir string, d Dataset) error {
+	files := make(map[string]string)
+	for _, image := range d.Images {
+		path := vocPath(dir, image.File)
+		if other, ok := files[path]; ok {
+			return errors.Errorf("%s and %s would both be written to %s", other, image.File, path)
+		}
+		files[path] = image.File
+	}
+	for _, image := range d.Images {
+		path := vocPath(dir, image.File)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := WriteVOC(f, image); err != nil {
+			f.Close()
+			return errors.Wrap(err, path)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadVOCDir reads every .xml file in the directory as Pascal VOC XML.
+// The categories of the dataset are the names of the objects, sorted.
+func ReadVOCDir(dir string) (*Dataset, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dataset{}
+	for _, info := range infos {
+		if info.IsDir() || strings.ToLower(filepath.Ext(info.Name())) != ".xml" {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		image, err := readVOCFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+		d.Images = append(d.Images, *image)
+	}
+	d.Categories = d.categories()
+	return d, nil
+}
+
+func readVOCFile(path string) (*Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadVOC(f)
+}
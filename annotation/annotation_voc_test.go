@@ -0,0 +1,100 @@
+package annotation_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/annotation"
+	"github.com/matryer/is"
+)
+
+func TestWriteVOC(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	is.NoErr(annotation.WriteVOC(&buf, testDataset().Images[0]))
+	s := buf.String()
+	is.True(strings.Contains(s, "<filename>street.jpg</filename>"))
+	is.True(strings.Contains(s, "<width>640</width>"))
+	is.True(strings.Contains(s, "<name>cars</name>"))
+	is.True(strings.Contains(s, "<xmin>101</xmin>"))
+	is.True(strings.Contains(s, "<ymin>201</ymin>"))
+	is.True(strings.Contains(s, "<xmax>400</xmax>"))
+	is.True(strings.Contains(s, "<ymax>300</ymax>"))
+}
+
+func TestReadVOC(t *testing.T) {
+	is := is.New(t)
+	image, err := annotation.ReadVOC(strings.NewReader(`<annotation>
+	<folder>VOC2012</folder>
+	<filename>2007_000027.jpg</filename>
+	<size>
+		<width>486</width>
+		<height>500</height>
+		<depth>3</depth>
+	</size>
+	<object>
+		<name>person</name>
+		<pose>Unspecified</pose>
+		<truncated>0</truncated>
+		<difficult>0</difficult>
+		<bndbox>
+			<xmin>174</xmin>
+			<ymin>101</ymin>
+			<xmax>349</xmax>
+			<ymax>351</ymax>
+		</bndbox>
+	</object>
+</annotation>`))
+	is.NoErr(err)
+	is.Equal(image.File, "2007_000027.jpg")
+	is.Equal(image.Width, 486)
+	is.Equal(image.Height, 500)
+	is.Equal(len(image.Annotations), 1)
+	is.Equal(image.Annotations[0].Category, "person")
+	is.Equal(image.Annotations[0].Box, annotation.Box{Left: 173, Top: 100, Width: 176, Height: 251})
+}
+
+func TestVOCDir(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "annotation")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	is.NoErr(annotation.WriteVOCDir(dir, testDataset()))
+	_, err = os.Stat(filepath.Join(dir, "street.xml"))
+	is.NoErr(err)
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not voc"), 0644))
+
+	d, err := annotation.ReadVOCDir(dir)
+	is.NoErr(err)
+	is.Equal(len(d.Images), 3)
+	is.Equal(d.Categories, []string{"cars", "people"})
+	image, ok := d.Image("street.jpg")
+	is.True(ok)
+	expected := testDataset().Images[0]
+	// scores are not stored in VOC
+	expected.Annotations[0].Score = 0
+	is.Equal(image, expected)
+}
+
+func TestVOCDirDuplicateNames(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "annotation")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	d := annotation.Dataset{
+		Images: []annotation.Image{
+			{File: "cam1/0001.jpg", Width: 640, Height: 480},
+			{File: "cam2/0001.jpg", Width: 640, Height: 480},
+		},
+	}
+	err = annotation.WriteVOCDir(dir, d)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "cam1/0001.jpg and cam2/0001.jpg"))
+	infos, err := ioutil.ReadDir(dir)
+	is.NoErr(err)
+	is.Equal(len(infos), 0) // nothing was written
+}
@@ -0,0 +1,246 @@
+// Package cascade finds objects with Objectbox, and classifies each
+// of them with Tagbox or Classificationbox, so for example cars can be
+// detected and then their make recognized in a single call.
+package cascade
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	_ "image/gif" // register the GIF decoder for cropping
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder for cropping
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/machinebox/sdk-go/classificationbox"
+	"github.com/machinebox/sdk-go/internal/imageutil"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/pkg/errors"
+)
+
+// Options control how objects are found and classified.
+type Options struct {
+	// Objectbox is the client used to find objects.
+	Objectbox *objectbox.Client
+	// Tagbox is the client used to tag each object. If nil, objects
+	// are not tagged.
+	Tagbox *tagbox.Client
+	// Classificationbox is the client used to classify each object
+	// with the image model ModelID. If nil, objects are not classified.
+	Classificationbox *classificationbox.Client
+	// ModelID is the ID of the Classificationbox model.
+	ModelID string
+	// FeatureKey is the key of the image feature of the model.
+	// Defaults to "image".
+	FeatureKey string
+	// Limit is the maximum number of classes predicted for each
+	// object. Defaults to 5.
+	Limit int
+	// Detectors are the IDs of the detectors whose objects are
+	// classified. If empty, objects from all detectors are classified.
+	Detectors []string
+	// MinScore is the minimum score of objects that are classified.
+	MinScore float64
+	// Padding is how much of the surrounding image is included with
+	// each object, as a fraction of its width and height on each side.
+	// For example, 0.1 makes a 100 pixel wide object a 120 pixel
+	// wide crop.
+	Padding float64
+	// Concurrency is the maximum number of objects that will be
+	// classified at the same time. Defaults to 4.
+	Concurrency int
+	// Quality is the JPEG quality used to encode crops.
+	// Defaults to 90.
+	Quality int
+}
+
+// Detection is an object that was found and classified.
+type Detection struct {
+	// Detector is the ID of the detector that found the object.
+	Detector string `json:"detector"`
+	// Object is the object.
+	Object objectbox.Object `json:"object"`
+	// Crop is the area of the image that was classified,
+	// which includes the padding.
+	Crop objectbox.Rect `json:"crop"`
+	// Tags are the tags of the crop, if Tagbox was used.
+	Tags []tagbox.Tag `json:"tags,omitempty"`
+	// CustomTags are the custom tags of the crop, if Tagbox was used.
+	CustomTags []tagbox.Tag `json:"custom_tags,omitempty"`
+	// Classes are the predicted classes of the crop, if
+	// Classificationbox was used.
+	Classes []classificationbox.Class `json:"classes,omitempty"`
+	// Error is why the object could not be classified. When both
+	// Tagbox and Classificationbox are used, a failure in one does
+	// not prevent the other, and Error has the errors of each that
+	// failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Check finds the objects in the image, and classifies each of them.
+// Detections are in the order of the Objectbox response.
+// Check only returns an error if the image could not be read, the
+// objects could not be found, or the context is cancelled; errors
+// classifying an object are reported in Detection.Error.
+func Check(ctx context.Context, image io.Reader, options *Options) ([]Detection, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	switch {
+	case options.Objectbox == nil:
+		return nil, errors.New("cascade: missing Objectbox")
+	case options.Tagbox == nil && options.Classificationbox == nil:
+		return nil, errors.New("cascade: missing Tagbox or Classificationbox")
+	case options.Classificationbox != nil && options.ModelID == "":
+		return nil, errors.New("cascade: missing ModelID")
+	}
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	img, _, err := decode(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode image")
+	}
+	response, err := options.Objectbox.Check(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return Classify(ctx, img, response, options)
+}
+
+// decode decodes the image data; Check can not call image.Decode
+// itself because its argument shadows the package.
+func decode(data []byte) (image.Image, string, error) {
+	return image.Decode(bytes.NewReader(data))
+}
+
+// Classify classifies the objects in a response that has already
+// been checked. The options.Objectbox client is not used.
+// See Check.
+func Classify(ctx context.Context, img image.Image, response objectbox.CheckResponse, options *Options) ([]Detection, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	detectors := make(map[string]bool)
+	for _, id := range options.Detectors {
+		detectors[id] = true
+	}
+	detections := []Detection{}
+	for _, detector := range response.Detectors {
+		if len(detectors) > 0 && !detectors[detector.ID] {
+			continue
+		}
+		for _, object := range detector.Objects {
+			if object.Score < options.MinScore {
+				continue
+			}
+			detections = append(detections, Detection{
+				Detector: detector.ID,
+				Object:   object,
+				Crop:     pad(object.Rect, options.Padding, img.Bounds()),
+			})
+		}
+	}
+	pending := make(chan *Detection)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for detection := range pending {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := classify(ctx, img, detection, options); err != nil {
+					detection.Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range detections {
+		pending <- &detections[i]
+	}
+	close(pending)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return detections, nil
+}
+
+// classify crops the detection from the image, and classifies it.
+func classify(ctx context.Context, img image.Image, detection *Detection, options *Options) error {
+	if detection.Crop.Width < 1 || detection.Crop.Height < 1 {
+		return errors.New("object is outside the image")
+	}
+	quality := options.Quality
+	if quality < 1 {
+		quality = 90
+	}
+	crop := detection.Crop
+	r := image.Rect(crop.Left, crop.Top, crop.Left+crop.Width, crop.Top+crop.Height)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, imageutil.SubImage(img, r), &jpeg.Options{Quality: quality}); err != nil {
+		return errors.Wrap(err, "encode crop")
+	}
+	// a failure in one box does not stop the other, so errors
+	// are collected
+	var failed []string
+	if options.Tagbox != nil {
+		response, err := options.Tagbox.Check(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			failed = append(failed, err.Error())
+		} else {
+			detection.Tags = response.Tags
+			detection.CustomTags = response.CustomTags
+		}
+	}
+	if options.Classificationbox != nil {
+		key := options.FeatureKey
+		if key == "" {
+			key = "image"
+		}
+		limit := options.Limit
+		if limit < 1 {
+			limit = 5
+		}
+		response, err := options.Classificationbox.Predict(ctx, options.ModelID, classificationbox.PredictRequest{
+			Limit: limit,
+			Inputs: []classificationbox.Feature{
+				classificationbox.FeatureImageBase64(key, base64.StdEncoding.EncodeToString(buf.Bytes())),
+			},
+		})
+		if err != nil {
+			failed = append(failed, err.Error())
+		} else {
+			detection.Classes = response.Classes
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// pad grows the rect by padding times its size on each side,
+// keeping it inside the bounds.
+func pad(rect objectbox.Rect, padding float64, bounds image.Rectangle) objectbox.Rect {
+	dx, dy := int(float64(rect.Width)*padding), int(float64(rect.Height)*padding)
+	r := image.Rect(rect.Left-dx, rect.Top-dy, rect.Left+rect.Width+dx, rect.Top+rect.Height+dy).Intersect(bounds)
+	return objectbox.Rect{
+		Left:   r.Min.X,
+		Top:    r.Min.Y,
+		Width:  r.Dx(),
+		Height: r.Dy(),
+	}
+}
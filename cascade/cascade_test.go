@@ -0,0 +1,254 @@
+package cascade_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/cascade"
+	"github.com/machinebox/sdk-go/classificationbox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+const objectboxResponse = `{
+	"success": true,
+	"detectors": [
+		{
+			"id": "cars",
+			"objects": [
+				{ "rect": { "left": 10, "top": 10, "width": 50, "height": 20 }, "score": 0.9 },
+				{ "rect": { "left": 0, "top": 0, "width": 20, "height": 20 }, "score": 0.3 },
+				{ "rect": { "left": 180, "top": 80, "width": 100, "height": 100 }, "score": 0.8 }
+			]
+		},
+		{
+			"id": "people",
+			"objects": [
+				{ "rect": { "left": 100, "top": 50, "width": 10, "height": 10 }, "score": 0.9 }
+			]
+		}
+	]
+}`
+
+func testImage(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// size gets the dimensions of the JPEG as a tag, so tests can see
+// which crop was sent.
+func size(r io.Reader) (string, error) {
+	config, err := jpeg.DecodeConfig(r)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%dx%d", config.Width, config.Height), nil
+}
+
+func TestCheckTagbox(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/objectbox/check":
+			io.WriteString(w, objectboxResponse)
+		case "/tagbox/check":
+			f, _, err := r.FormFile("file")
+			is.NoErr(err)
+			defer f.Close()
+			tag, err := size(f)
+			is.NoErr(err)
+			if tag == "20x20" {
+				http.Error(w, `{"success": false, "error": "too small"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(struct {
+				Success bool `json:"success"`
+				tagbox.CheckResponse
+			}{
+				Success: true,
+				CheckResponse: tagbox.CheckResponse{
+					Tags:       []tagbox.Tag{{Tag: tag, Confidence: 0.9}},
+					CustomTags: []tagbox.Tag{{Tag: "ford", Confidence: 0.8}},
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	detections, err := cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox: objectbox.New(srv.URL),
+		Tagbox:    tagbox.New(srv.URL),
+		Detectors: []string{"cars"},
+		Padding:   0.2,
+	})
+	is.NoErr(err)
+	is.Equal(len(detections), 3)
+
+	is.Equal(detections[0].Detector, "cars")
+	is.Equal(detections[0].Object.Score, 0.9)
+	is.Equal(detections[0].Crop, objectbox.Rect{Left: 0, Top: 6, Width: 70, Height: 28})
+	is.Equal(detections[0].Error, "")
+	is.Equal(detections[0].Tags[0].Tag, "70x28")
+	is.Equal(detections[0].CustomTags[0].Tag, "ford")
+
+	is.Equal(detections[1].Crop, objectbox.Rect{Left: 0, Top: 0, Width: 24, Height: 24})
+	is.Equal(detections[1].Tags[0].Tag, "24x24")
+
+	// mostly off the edge of the image
+	is.Equal(detections[2].Crop, objectbox.Rect{Left: 160, Top: 60, Width: 40, Height: 40})
+	is.Equal(detections[2].Tags[0].Tag, "40x40")
+
+	detections, err = cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox: objectbox.New(srv.URL),
+		Tagbox:    tagbox.New(srv.URL),
+		MinScore:  0.2,
+	})
+	is.NoErr(err)
+	is.Equal(len(detections), 4)
+	is.Equal(detections[1].Error, "tagbox: too small")
+	is.Equal(detections[3].Detector, "people")
+}
+
+func TestCheckClassificationbox(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/objectbox/check":
+			io.WriteString(w, objectboxResponse)
+		case "/classificationbox/models/makes/predict":
+			var request classificationbox.PredictRequest
+			is.NoErr(json.NewDecoder(r.Body).Decode(&request))
+			is.Equal(request.Limit, 5)
+			is.Equal(len(request.Inputs), 1)
+			is.Equal(request.Inputs[0].Key, "photo")
+			is.Equal(request.Inputs[0].Type, "image_base64")
+			data, err := base64.StdEncoding.DecodeString(request.Inputs[0].Value)
+			is.NoErr(err)
+			class, err := size(bytes.NewReader(data))
+			is.NoErr(err)
+			json.NewEncoder(w).Encode(struct {
+				Success bool `json:"success"`
+				classificationbox.PredictResponse
+			}{
+				Success: true,
+				PredictResponse: classificationbox.PredictResponse{
+					Classes: []classificationbox.Class{{ID: class, Score: 0.7}},
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	detections, err := cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox:         objectbox.New(srv.URL),
+		Classificationbox: classificationbox.New(srv.URL),
+		ModelID:           "makes",
+		FeatureKey:        "photo",
+		Detectors:         []string{"people"},
+	})
+	is.NoErr(err)
+	is.Equal(len(detections), 1)
+	is.Equal(detections[0].Tags, nil)
+	is.Equal(len(detections[0].Classes), 1)
+	is.Equal(detections[0].Classes[0].ID, "10x10")
+}
+
+func TestCheckErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Tagbox: tagbox.New("http://tagbox.local"),
+	})
+	is.Equal(err.Error(), "cascade: missing Objectbox")
+	_, err = cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox: objectbox.New("http://objectbox.local"),
+	})
+	is.Equal(err.Error(), "cascade: missing Tagbox or Classificationbox")
+	_, err = cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox:         objectbox.New("http://objectbox.local"),
+		Classificationbox: classificationbox.New("http://classificationbox.local"),
+	})
+	is.Equal(err.Error(), "cascade: missing ModelID")
+	_, err = cascade.Check(context.Background(), bytes.NewReader([]byte("not an image")), &cascade.Options{
+		Objectbox: objectbox.New("http://objectbox.local"),
+		Tagbox:    tagbox.New("http://tagbox.local"),
+	})
+	is.Equal(err.Error(), "decode image: image: unknown format")
+}
+
+func TestClassifyCancel(t *testing.T) {
+	is := is.New(t)
+	var response objectbox.CheckResponse
+	is.NoErr(json.Unmarshal([]byte(objectboxResponse), &response))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cascade.Classify(ctx, image.NewRGBA(image.Rect(0, 0, 200, 100)), response, &cascade.Options{
+		Tagbox: tagbox.New("http://tagbox.local"),
+	})
+	is.Equal(err, context.Canceled)
+}
+
+func TestCheckBothBoxesErrors(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/objectbox/check":
+			io.WriteString(w, objectboxResponse)
+		case "/tagbox/check":
+			http.Error(w, `{"success": false, "error": "tagbox is down"}`, http.StatusInternalServerError)
+		case "/classificationbox/models/makes/predict":
+			var request classificationbox.PredictRequest
+			is.NoErr(json.NewDecoder(r.Body).Decode(&request))
+			data, err := base64.StdEncoding.DecodeString(request.Inputs[0].Value)
+			is.NoErr(err)
+			class, err := size(bytes.NewReader(data))
+			is.NoErr(err)
+			if class == "20x20" {
+				http.Error(w, `{"success": false, "error": "too small"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(struct {
+				Success bool `json:"success"`
+				classificationbox.PredictResponse
+			}{
+				Success: true,
+				PredictResponse: classificationbox.PredictResponse{
+					Classes: []classificationbox.Class{{ID: class, Score: 0.7}},
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	detections, err := cascade.Check(context.Background(), bytes.NewReader(testImage(t)), &cascade.Options{
+		Objectbox:         objectbox.New(srv.URL),
+		Tagbox:            tagbox.New(srv.URL),
+		Classificationbox: classificationbox.New(srv.URL),
+		ModelID:           "makes",
+		Detectors:         []string{"cars"},
+	})
+	is.NoErr(err)
+	is.Equal(len(detections), 3)
+	// classificationbox still ran even though tagbox failed
+	is.Equal(detections[0].Error, "tagbox: tagbox is down")
+	is.Equal(detections[0].Tags, nil)
+	is.Equal(len(detections[0].Classes), 1)
+	is.Equal(detections[1].Error, "tagbox: tagbox is down; classificationbox: too small")
+	is.Equal(detections[1].Classes, nil)
+}
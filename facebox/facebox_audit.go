@@ -0,0 +1,321 @@
+package facebox
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// TaughtFace describes a face that has been taught to facebox.
+type TaughtFace struct {
+	// ID is the unique identifier of the taught image.
+	ID string `json:"id"`
+	// Name is the name of the person.
+	Name string `json:"name"`
+}
+
+// AuditOptions control the behaviour of Audit.
+type AuditOptions struct {
+	// Concurrency is the maximum number of SimilarID requests that
+	// will be made at the same time. Defaults to 4.
+	Concurrency int
+	// Neighbours is the number of most similar faces considered
+	// for each ID. Defaults to 5.
+	Neighbours int
+	// MislabelRatio is the fraction of neighbours that must carry a
+	// different name for an ID to be reported as mislabeled.
+	// Defaults to 0.5 (more than half).
+	MislabelRatio float64
+	// DuplicateConfidence is the confidence at or above which two
+	// faces are considered near-duplicates. Defaults to 0.95.
+	DuplicateConfidence float64
+	// MinExamples is the number of examples each person should have.
+	// Defaults to 3.
+	MinExamples int
+}
+
+// AuditReport describes problems found in the faces taught to facebox.
+type AuditReport struct {
+	// Mislabeled are the IDs whose neighbours mostly carry a
+	// different name.
+	Mislabeled []AuditMislabeled `json:"mislabeled"`
+	// Duplicates are groups of near-duplicate images.
+	Duplicates []AuditDuplicates `json:"duplicates"`
+	// TooFewExamples are the people with fewer than
+	// AuditOptions.MinExamples examples.
+	TooFewExamples []AuditPerson `json:"too_few_examples"`
+	// Actions are the suggested changes that would fix the problems.
+	Actions []AuditAction `json:"actions"`
+	// Errors are the IDs that could not be audited.
+	Errors []AuditError `json:"errors"`
+}
+
+// AuditMislabeled describes an ID that is probably mislabeled.
+type AuditMislabeled struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// SuggestedName is the most common name among the neighbours.
+	SuggestedName string `json:"suggested_name"`
+	// Ratio is the fraction of neighbours with a different name.
+	Ratio float64 `json:"ratio"`
+}
+
+// AuditDuplicates is a group of near-duplicate images.
+type AuditDuplicates struct {
+	// Keep is the ID suggested to be kept.
+	Keep string `json:"keep"`
+	// Faces are all the faces in the group, including Keep.
+	Faces []TaughtFace `json:"faces"`
+}
+
+// AuditPerson describes the examples for a person.
+type AuditPerson struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// AuditActionType is the kind of change suggested by an AuditAction.
+type AuditActionType string
+
+const (
+	// AuditActionRename suggests calling Rename.
+	AuditActionRename AuditActionType = "rename"
+	// AuditActionRemove suggests calling Remove.
+	AuditActionRemove AuditActionType = "remove"
+)
+
+// AuditAction is a suggested change.
+type AuditAction struct {
+	Action AuditActionType `json:"action"`
+	ID     string          `json:"id"`
+	// Name is the new name for AuditActionRename.
+	Name string `json:"name,omitempty"`
+	// Reason describes why the action is suggested.
+	Reason string `json:"reason"`
+}
+
+// AuditError describes an ID that could not be audited.
+type AuditError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// Audit looks for mislabeled faces, near-duplicate images and people
+// with too few examples among the taught faces by calling SimilarID
+// for each one.
+// Facebox cannot list the faces it has been taught, so they must be
+// provided, for example from a TeachDirReport or with
+// JournalFaces.TaughtFaces.
+func (c *Client) Audit(ctx context.Context, faces []TaughtFace, options *AuditOptions) (*AuditReport, error) {
+	if options == nil {
+		options = &AuditOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	neighbours := options.Neighbours
+	if neighbours < 1 {
+		neighbours = 5
+	}
+	mislabelRatio := options.MislabelRatio
+	if mislabelRatio <= 0 {
+		mislabelRatio = 0.5
+	}
+	duplicateConfidence := options.DuplicateConfidence
+	if duplicateConfidence <= 0 {
+		duplicateConfidence = 0.95
+	}
+	minExamples := options.MinExamples
+	if minExamples < 1 {
+		minExamples = 3
+	}
+	similars := make([][]Similar, len(faces))
+	errs := make([]error, len(faces))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				similars[i], errs[i] = c.SimilarID(faces[i].ID)
+			}
+		}()
+	}
+	for i := range faces {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &AuditReport{}
+	index := make(map[string]int, len(faces))
+	for i, face := range faces {
+		index[face.ID] = i
+	}
+	dupes := newUnionFind(len(faces))
+	for i, face := range faces {
+		if errs[i] != nil {
+			report.Errors = append(report.Errors, AuditError{
+				ID:    face.ID,
+				Error: errors.Wrap(errs[i], "similar").Error(),
+			})
+			continue
+		}
+		var considered, different int
+		counts := make(map[string]int)
+		for _, similar := range similars[i] {
+			if similar.ID == face.ID {
+				continue
+			}
+			if considered == neighbours {
+				break
+			}
+			considered++
+			if similar.Name != face.Name {
+				different++
+				counts[similar.Name]++
+			}
+			if j, ok := index[similar.ID]; ok && similar.Confidence >= duplicateConfidence {
+				dupes.union(i, j)
+			}
+		}
+		if considered == 0 {
+			continue
+		}
+		ratio := float64(different) / float64(considered)
+		if ratio <= mislabelRatio {
+			continue
+		}
+		mislabeled := AuditMislabeled{
+			ID:            face.ID,
+			Name:          face.Name,
+			SuggestedName: mostCommon(counts),
+			Ratio:         ratio,
+		}
+		report.Mislabeled = append(report.Mislabeled, mislabeled)
+		report.Actions = append(report.Actions, AuditAction{
+			Action: AuditActionRename,
+			ID:     face.ID,
+			Name:   mislabeled.SuggestedName,
+			Reason: "neighbours are mostly " + mislabeled.SuggestedName,
+		})
+	}
+
+	groups := make(map[int][]TaughtFace)
+	for i, face := range faces {
+		root := dupes.find(i)
+		groups[root] = append(groups[root], face)
+	}
+	for i := range faces {
+		group := groups[i]
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(a, b int) bool {
+			return group[a].ID < group[b].ID
+		})
+		duplicates := AuditDuplicates{Keep: group[0].ID, Faces: group}
+		report.Duplicates = append(report.Duplicates, duplicates)
+		sameName := true
+		for _, face := range group {
+			if face.Name != group[0].Name {
+				sameName = false
+			}
+		}
+		if !sameName {
+			// mislabeled faces must be resolved before
+			// any duplicates are removed
+			continue
+		}
+		for _, face := range group[1:] {
+			report.Actions = append(report.Actions, AuditAction{
+				Action: AuditActionRemove,
+				ID:     face.ID,
+				Reason: "near-duplicate of " + duplicates.Keep,
+			})
+		}
+	}
+
+	examples := make(map[string]int)
+	for _, face := range faces {
+		examples[face.Name]++
+	}
+	for name, count := range examples {
+		if count < minExamples {
+			report.TooFewExamples = append(report.TooFewExamples, AuditPerson{
+				Name:  name,
+				Count: count,
+			})
+		}
+	}
+	sort.Slice(report.TooFewExamples, func(i, j int) bool {
+		return report.TooFewExamples[i].Name < report.TooFewExamples[j].Name
+	})
+	return report, nil
+}
+
+// ApplyAuditAction performs the suggested action.
+func (c *Client) ApplyAuditAction(action AuditAction) error {
+	switch action.Action {
+	case AuditActionRename:
+		return c.Rename(action.ID, action.Name)
+	case AuditActionRemove:
+		return c.Remove(action.ID)
+	}
+	return errors.Errorf("unknown action %q", action.Action)
+}
+
+// mostCommon gets the key with the highest count, preferring the
+// alphabetically first key when counts are equal.
+func mostCommon(counts map[string]int) string {
+	var best string
+	var bestCount int
+	for key, count := range counts {
+		if count > bestCount || (count == bestCount && key < best) {
+			best, bestCount = key, count
+		}
+	}
+	return best
+}
+
+// unionFind is a disjoint set of indexes.
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	u := make(unionFind, n)
+	for i := range u {
+		u[i] = i
+	}
+	return u
+}
+
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+func (u unionFind) union(i, j int) {
+	ri, rj := u.find(i), u.find(j)
+	if ri == rj {
+		return
+	}
+	if ri < rj {
+		u[rj] = ri
+	} else {
+		u[ri] = rj
+	}
+}
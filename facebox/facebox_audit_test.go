@@ -0,0 +1,96 @@
+package facebox_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestAudit(t *testing.T) {
+	is := is.New(t)
+	responses := map[string]string{
+		"john1": `{"success": true, "similar": [
+			{"id": "john2", "name": "John Lennon", "confidence": 0.97},
+			{"id": "john3", "name": "John Lennon", "confidence": 0.7}
+		]}`,
+		"john2": `{"success": true, "similar": [
+			{"id": "john1", "name": "John Lennon", "confidence": 0.97},
+			{"id": "john3", "name": "John Lennon", "confidence": 0.7}
+		]}`,
+		"john3": `{"success": true, "similar": [
+			{"id": "john1", "name": "John Lennon", "confidence": 0.7},
+			{"id": "john2", "name": "John Lennon", "confidence": 0.7}
+		]}`,
+		"ringo1": `{"success": true, "similar": [
+			{"id": "john1", "name": "John Lennon", "confidence": 0.8},
+			{"id": "john2", "name": "John Lennon", "confidence": 0.8},
+			{"id": "john3", "name": "John Lennon", "confidence": 0.8}
+		]}`,
+		"paul1": `{"success": false, "error": "something went wrong"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/facebox/similar")
+		io.WriteString(w, responses[r.URL.Query().Get("id")])
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	faces := []facebox.TaughtFace{
+		{ID: "john1", Name: "John Lennon"},
+		{ID: "john2", Name: "John Lennon"},
+		{ID: "john3", Name: "John Lennon"},
+		{ID: "ringo1", Name: "Ringo Starr"},
+		{ID: "paul1", Name: "Paul McCartney"},
+	}
+	report, err := fb.Audit(context.Background(), faces, nil)
+	is.NoErr(err)
+
+	is.Equal(len(report.Mislabeled), 1)
+	is.Equal(report.Mislabeled[0].ID, "ringo1")
+	is.Equal(report.Mislabeled[0].SuggestedName, "John Lennon")
+	is.Equal(report.Mislabeled[0].Ratio, 1.0)
+
+	is.Equal(len(report.Duplicates), 1)
+	is.Equal(report.Duplicates[0].Keep, "john1")
+	is.Equal(len(report.Duplicates[0].Faces), 2)
+	is.Equal(report.Duplicates[0].Faces[1].ID, "john2")
+
+	is.Equal(len(report.TooFewExamples), 2)
+	is.Equal(report.TooFewExamples[0], facebox.AuditPerson{Name: "Paul McCartney", Count: 1})
+	is.Equal(report.TooFewExamples[1], facebox.AuditPerson{Name: "Ringo Starr", Count: 1})
+
+	is.Equal(len(report.Actions), 2)
+	is.Equal(report.Actions[0].Action, facebox.AuditActionRename)
+	is.Equal(report.Actions[0].ID, "ringo1")
+	is.Equal(report.Actions[0].Name, "John Lennon")
+	is.Equal(report.Actions[1].Action, facebox.AuditActionRemove)
+	is.Equal(report.Actions[1].ID, "john2")
+
+	is.Equal(len(report.Errors), 1)
+	is.Equal(report.Errors[0].ID, "paul1")
+	is.Equal(report.Errors[0].Error, "similar: facebox: something went wrong")
+}
+
+func TestApplyAuditAction(t *testing.T) {
+	is := is.New(t)
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.FormValue("name"))
+		io.WriteString(w, `{"success": true}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	is.NoErr(fb.ApplyAuditAction(facebox.AuditAction{Action: facebox.AuditActionRename, ID: "ringo1", Name: "John Lennon"}))
+	is.NoErr(fb.ApplyAuditAction(facebox.AuditAction{Action: facebox.AuditActionRemove, ID: "john2"}))
+	err := fb.ApplyAuditAction(facebox.AuditAction{Action: "explode", ID: "john2"})
+	is.Equal(err.Error(), `unknown action "explode"`)
+	is.Equal(calls, []string{
+		"PATCH /facebox/teach/ringo1 John Lennon",
+		"DELETE /facebox/teach/john2 ",
+	})
+}
@@ -14,6 +14,12 @@ import (
 
 // Check checks the image in the io.Reader for faces.
 func (c *Client) Check(image io.Reader) ([]Face, error) {
+	return c.CheckWithOptions(image, nil)
+}
+
+// CheckWithOptions checks the image in the io.Reader for faces,
+// using the CheckOptions to control the results.
+func (c *Client) CheckWithOptions(image io.Reader, options *CheckOptions) ([]Face, error) {
 	var buf bytes.Buffer
 	w := multipart.NewWriter(&buf)
 	fw, err := w.CreateFormFile("file", "image.dat")
@@ -24,6 +30,9 @@ func (c *Client) Check(image io.Reader) ([]Face, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := options.apply(w.WriteField); err != nil {
+		return nil, errors.Wrap(err, "setting options")
+	}
 	if err = w.Close(); err != nil {
 		return nil, err
 	}
@@ -47,11 +56,17 @@ func (c *Client) Check(image io.Reader) ([]Face, error) {
 	if err != nil {
 		return nil, err
 	}
-	return checkResponse.Faces, nil
+	return options.filter(checkResponse.Faces), nil
 }
 
 // CheckURL checks the image at the specified URL for faces.
 func (c *Client) CheckURL(imageURL *url.URL) ([]Face, error) {
+	return c.CheckURLWithOptions(imageURL, nil)
+}
+
+// CheckURLWithOptions checks the image at the specified URL for faces,
+// using the CheckOptions to control the results.
+func (c *Client) CheckURLWithOptions(imageURL *url.URL, options *CheckOptions) ([]Face, error) {
 	u, err := url.Parse(c.addr + "/facebox/check")
 	if err != nil {
 		return nil, err
@@ -64,6 +79,13 @@ func (c *Client) CheckURL(imageURL *url.URL) ([]Face, error) {
 	}
 	form := url.Values{}
 	form.Set("url", imageURL.String())
+	formset := func(key, value string) error {
+		form.Set(key, value)
+		return nil
+	}
+	if err := options.apply(formset); err != nil {
+		return nil, errors.Wrap(err, "setting options")
+	}
 	req, err := http.NewRequest("POST", u.String(), strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
@@ -77,20 +99,24 @@ func (c *Client) CheckURL(imageURL *url.URL) ([]Face, error) {
 	if err != nil {
 		return nil, err
 	}
-	return checkResponse.Faces, nil
+	return options.filter(checkResponse.Faces), nil
 }
 
 // CheckBase64 checks the Base64 encoded image for faces.
 func (c *Client) CheckBase64(data string) ([]Face, error) {
-	return c.checkBase64WithOptions(data, nil)
+	return c.CheckBase64WithOptions(data, nil)
 }
 
 // CheckBase64WithFaceprint checks the Base64 encoded image for faces and the object returned including the faceprints
 func (c *Client) CheckBase64WithFaceprint(data string) ([]Face, error) {
-	return c.checkBase64WithOptions(data, map[string]string{"faceprint": "true"})
+	options := NewCheckOptions()
+	options.Faceprint()
+	return c.CheckBase64WithOptions(data, options)
 }
 
-func (c *Client) checkBase64WithOptions(data string, options map[string]string) ([]Face, error) {
+// CheckBase64WithOptions checks the Base64 encoded image for faces,
+// using the CheckOptions to control the results.
+func (c *Client) CheckBase64WithOptions(data string, options *CheckOptions) ([]Face, error) {
 	u, err := url.Parse(c.addr + "/facebox/check")
 	if err != nil {
 		return nil, err
@@ -100,8 +126,12 @@ func (c *Client) checkBase64WithOptions(data string, options map[string]string)
 	}
 	form := url.Values{}
 	form.Set("base64", data)
-	for k, v := range options {
-		form.Set(k, v)
+	formset := func(key, value string) error {
+		form.Set(key, value)
+		return nil
+	}
+	if err := options.apply(formset); err != nil {
+		return nil, errors.Wrap(err, "setting options")
 	}
 	req, err := http.NewRequest("POST", u.String(), strings.NewReader(form.Encode()))
 	if err != nil {
@@ -116,5 +146,5 @@ func (c *Client) checkBase64WithOptions(data string, options map[string]string)
 	if err != nil {
 		return nil, err
 	}
-	return checkResponse.Faces, nil
+	return options.filter(checkResponse.Faces), nil
 }
@@ -0,0 +1,123 @@
+package facebox
+
+import (
+	"sort"
+)
+
+// CheckOptions are additional options that control the results
+// of the Check and Similars methods.
+// Options that facebox does not support are applied by the client
+// after the results are returned.
+type CheckOptions struct {
+	fields        map[string]string
+	minConfidence float64
+	maxFaces      int
+	onlyMatched   bool
+}
+
+// NewCheckOptions makes a new CheckOptions object.
+func NewCheckOptions() *CheckOptions {
+	return &CheckOptions{
+		fields: make(map[string]string),
+	}
+}
+
+// Faceprint includes the faceprint of each face in the results.
+// Faceprint has no effect on Similars.
+func (o *CheckOptions) Faceprint() {
+	o.fields["faceprint"] = "true"
+}
+
+// MinConfidence sets the minimum confidence of a match.
+// Faces matched with a lower confidence are treated as unmatched,
+// and similar faces with a lower confidence are removed.
+func (o *CheckOptions) MinConfidence(v float64) {
+	o.minConfidence = v
+}
+
+// MaxFaces sets the maximum number of faces in the results.
+// The largest faces are kept.
+func (o *CheckOptions) MaxFaces(n int) {
+	o.maxFaces = n
+}
+
+// OnlyMatched removes faces that do not match a known person,
+// or that have no similar faces.
+func (o *CheckOptions) OnlyMatched() {
+	o.onlyMatched = true
+}
+
+// apply calls writeField for each field.
+// If o is nil, apply is noop.
+func (o *CheckOptions) apply(writeField func(key, value string) error) error {
+	if o == nil {
+		return nil
+	}
+	for k, v := range o.fields {
+		if err := writeField(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filter applies the client side options to the faces.
+// If o is nil, the faces are returned unchanged.
+func (o *CheckOptions) filter(faces []Face) []Face {
+	if o == nil {
+		return faces
+	}
+	filtered := make([]Face, 0, len(faces))
+	for _, face := range faces {
+		if face.Matched && face.Confidence < o.minConfidence {
+			face.Matched = false
+			face.ID = ""
+			face.Name = ""
+		}
+		if o.onlyMatched && !face.Matched {
+			continue
+		}
+		filtered = append(filtered, face)
+	}
+	if o.maxFaces > 0 && len(filtered) > o.maxFaces {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Rect.area() > filtered[j].Rect.area()
+		})
+		filtered = filtered[:o.maxFaces]
+	}
+	return filtered
+}
+
+// filterSimilars applies the client side options to the faces.
+// If o is nil, the faces are returned unchanged.
+func (o *CheckOptions) filterSimilars(faces []SimilarFace) []SimilarFace {
+	if o == nil {
+		return faces
+	}
+	filtered := make([]SimilarFace, 0, len(faces))
+	for _, face := range faces {
+		similars := make([]Similar, 0, len(face.SimilarFaces))
+		for _, similar := range face.SimilarFaces {
+			if similar.Confidence >= o.minConfidence {
+				similars = append(similars, similar)
+			}
+		}
+		face.SimilarFaces = similars
+		if o.onlyMatched && len(face.SimilarFaces) == 0 {
+			continue
+		}
+		filtered = append(filtered, face)
+	}
+	if o.maxFaces > 0 && len(filtered) > o.maxFaces {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Rect.area() > filtered[j].Rect.area()
+		})
+		filtered = filtered[:o.maxFaces]
+	}
+	return filtered
+}
+
+// area gets the area of the Rect in pixels.
+func (r Rect) area() int {
+	return r.Width * r.Height
+}
@@ -0,0 +1,158 @@
+package facebox_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+const checkOptionsResponse = `{
+	"success": true,
+	"faces": [
+		{
+			"rect": { "top": 0, "left": 0, "width": 120, "height": 120 },
+			"id": "file1.jpg",
+			"name": "John Lennon",
+			"matched": true,
+			"confidence": 0.8,
+			"faceprint": "faceprint1"
+		},
+		{
+			"rect": { "top": 200, "left": 200, "width": 100, "height": 100 },
+			"id": "file6.jpg",
+			"name": "Ringo Starr",
+			"matched": true,
+			"confidence": 0.5,
+			"faceprint": "faceprint2"
+		},
+		{
+			"rect": { "top": 50, "left": 50, "width": 150, "height": 150 },
+			"matched": false,
+			"faceprint": "faceprint3"
+		}
+	]
+}`
+
+func TestCheckWithOptions(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		is.Equal(r.FormValue("faceprint"), "true")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		is.Equal(string(b), `(pretend this is image data)`)
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+
+	options := facebox.NewCheckOptions()
+	options.Faceprint()
+	faces, err := fb.CheckWithOptions(strings.NewReader(`(pretend this is image data)`), options)
+	is.NoErr(err)
+	is.Equal(len(faces), 3)
+	is.Equal(faces[0].Faceprint, "faceprint1")
+
+	options.MinConfidence(0.6)
+	faces, err = fb.CheckWithOptions(strings.NewReader(`(pretend this is image data)`), options)
+	is.NoErr(err)
+	is.Equal(len(faces), 3)
+	is.Equal(faces[1].Matched, false)
+	is.Equal(faces[1].Name, "")
+	is.Equal(faces[1].Faceprint, "faceprint2")
+
+	options.OnlyMatched()
+	faces, err = fb.CheckWithOptions(strings.NewReader(`(pretend this is image data)`), options)
+	is.NoErr(err)
+	is.Equal(len(faces), 1)
+	is.Equal(faces[0].Name, "John Lennon")
+}
+
+func TestCheckURLWithOptions(t *testing.T) {
+	is := is.New(t)
+	imageURL, err := url.Parse("https://test.machinebox.io/image1.png")
+	is.NoErr(err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		is.Equal(r.FormValue("url"), imageURL.String())
+		is.Equal(r.FormValue("faceprint"), "")
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	options := facebox.NewCheckOptions()
+	options.MaxFaces(2)
+	faces, err := fb.CheckURLWithOptions(imageURL, options)
+	is.NoErr(err)
+	is.Equal(len(faces), 2)
+	is.Equal(faces[0].Faceprint, "faceprint3")
+	is.Equal(faces[1].Faceprint, "faceprint1")
+}
+
+func TestCheckBase64WithOptions(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		is.Equal(r.FormValue("base64"), "base64Str")
+		is.Equal(r.FormValue("faceprint"), "true")
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	options := facebox.NewCheckOptions()
+	options.Faceprint()
+	options.OnlyMatched()
+	faces, err := fb.CheckBase64WithOptions("base64Str", options)
+	is.NoErr(err)
+	is.Equal(len(faces), 2)
+}
+
+func TestSimilarsWithOptions(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/similars")
+		is.Equal(r.URL.Query().Get("limit"), "5")
+		io.WriteString(w, `{
+			"success": true,
+			"faces": [
+				{
+					"rect": { "top": 0, "left": 0, "width": 120, "height": 120 },
+					"similar_faces": [
+						{"id": "file1.jpg", "name": "John Lennon", "confidence": 0.8},
+						{"id": "file2.jpg", "name": "John Lennon", "confidence": 0.4}
+					]
+				},
+				{
+					"rect": { "top": 200, "left": 200, "width": 100, "height": 100 },
+					"similar_faces": [
+						{"id": "file6.jpg", "name": "Ringo Starr", "confidence": 0.3}
+					]
+				}
+			]
+		}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	options := facebox.NewCheckOptions()
+	options.MinConfidence(0.5)
+	faces, err := fb.SimilarsWithOptions(strings.NewReader(`(pretend this is image data)`), 0, options)
+	is.NoErr(err)
+	is.Equal(len(faces), 2)
+	is.Equal(len(faces[0].SimilarFaces), 1)
+	is.Equal(len(faces[1].SimilarFaces), 0)
+	options.OnlyMatched()
+	faces, err = fb.SimilarsWithOptions(strings.NewReader(`(pretend this is image data)`), 0, options)
+	is.NoErr(err)
+	is.Equal(len(faces), 1)
+	is.Equal(faces[0].SimilarFaces[0].ID, "file1.jpg")
+}
@@ -0,0 +1,225 @@
+package facebox
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ClusterOptions control the behaviour of ClusterFaceprints.
+type ClusterOptions struct {
+	// Threshold is the average confidence at or above which two
+	// clusters are considered to be the same person.
+	// Defaults to 0.6.
+	Threshold float64
+	// BatchSize is the maximum number of candidates sent in each
+	// CompareFaceprints request. Defaults to 100.
+	BatchSize int
+	// Concurrency is the maximum number of CompareFaceprints requests
+	// that will be made at the same time. Defaults to 4.
+	Concurrency int
+}
+
+// Cluster is a group of faceprints that probably belong to the
+// same person.
+type Cluster struct {
+	// Faceprints are the members of the cluster.
+	Faceprints []string `json:"faceprints"`
+	// Representative is the member most similar to all the others.
+	Representative string `json:"representative"`
+	// Confidence is the average confidence between members of
+	// the cluster. It is 1 for clusters with a single member.
+	Confidence float64 `json:"confidence"`
+}
+
+// ClusterFaceprints groups faceprints (see CheckBase64WithFaceprint) into
+// clusters of faces that probably belong to the same person, so that each
+// cluster can be named once with TeachCluster.
+// Every pair of faceprints is compared using CompareFaceprints, and
+// clusters are merged (average linkage) while their confidence is at or
+// above the threshold.
+// Clusters are ordered largest first.
+func (c *Client) ClusterFaceprints(ctx context.Context, faceprints []string, options *ClusterOptions) ([]Cluster, error) {
+	if options == nil {
+		options = &ClusterOptions{}
+	}
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = 0.6
+	}
+	batchSize := options.BatchSize
+	if batchSize < 1 {
+		batchSize = 100
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	n := len(faceprints)
+	sims := make([][]float64, n)
+	for i := range sims {
+		sims[i] = make([]float64, n)
+		sims[i][i] = 1
+	}
+	type batch struct {
+		target, from, to int
+	}
+	batches := make(chan batch)
+	var errOnce sync.Once
+	var compareErr error
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				if ctx.Err() != nil {
+					continue
+				}
+				confidences, err := c.CompareFaceprints(faceprints[b.target], faceprints[b.from:b.to])
+				if err == nil && len(confidences) != b.to-b.from {
+					err = errors.Errorf("expected %d confidences but got %d", b.to-b.from, len(confidences))
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						compareErr = errors.Wrap(err, "compare faceprints")
+					})
+					continue
+				}
+				// each batch writes to distinct cells
+				for k, confidence := range confidences {
+					sims[b.target][b.from+k] = confidence
+					sims[b.from+k][b.target] = confidence
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		for from := i + 1; from < n; from += batchSize {
+			to := from + batchSize
+			if to > n {
+				to = n
+			}
+			batches <- batch{target: i, from: from, to: to}
+		}
+	}
+	close(batches)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if compareErr != nil {
+		return nil, compareErr
+	}
+	groups := clusterAverageLinkage(sims, threshold)
+	clusters := make([]Cluster, len(groups))
+	for i, members := range groups {
+		clusters[i] = makeCluster(faceprints, sims, members)
+	}
+	return clusters, nil
+}
+
+// TeachCluster teaches facebox every faceprint in the cluster with
+// the specified name. Each faceprint is taught with an ID made from
+// the hash of the faceprint.
+func (c *Client) TeachCluster(cluster Cluster, name string) error {
+	for _, faceprint := range cluster.Faceprints {
+		id := IDFromContentHash("", []byte(faceprint))
+		if err := c.TeachFaceprint(faceprint, id, name); err != nil {
+			return errors.Wrapf(err, "teach %s", id)
+		}
+	}
+	return nil
+}
+
+// clusterAverageLinkage performs agglomerative clustering on the
+// similarity matrix, repeatedly merging the two clusters with the
+// highest average similarity until none is at or above threshold.
+// Groups are returned largest first.
+func clusterAverageLinkage(sims [][]float64, threshold float64) [][]int {
+	n := len(sims)
+	groups := make([][]int, n)
+	link := make([][]float64, n)
+	for i := range groups {
+		groups[i] = []int{i}
+		link[i] = make([]float64, n)
+		copy(link[i], sims[i])
+	}
+	active := make([]bool, n)
+	for i := range active {
+		active[i] = true
+	}
+	for {
+		a, b := -1, -1
+		best := threshold
+		for i := 0; i < n; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && link[i][j] >= best {
+					a, b, best = i, j, link[i][j]
+				}
+			}
+		}
+		if a < 0 {
+			break
+		}
+		na, nb := float64(len(groups[a])), float64(len(groups[b]))
+		for k := 0; k < n; k++ {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			link[a][k] = (na*link[a][k] + nb*link[b][k]) / (na + nb)
+			link[k][a] = link[a][k]
+		}
+		groups[a] = append(groups[a], groups[b]...)
+		active[b] = false
+	}
+	var result [][]int
+	for i, group := range groups {
+		if active[i] {
+			sort.Ints(group)
+			result = append(result, group)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// makeCluster makes a Cluster from the member indexes.
+func makeCluster(faceprints []string, sims [][]float64, members []int) Cluster {
+	cluster := Cluster{
+		Faceprints: make([]string, len(members)),
+		Confidence: 1,
+	}
+	for i, member := range members {
+		cluster.Faceprints[i] = faceprints[member]
+	}
+	cluster.Representative = faceprints[members[0]]
+	if len(members) == 1 {
+		return cluster
+	}
+	var total float64
+	bestMean := -1.0
+	for _, i := range members {
+		var sum float64
+		for _, j := range members {
+			if i != j {
+				sum += sims[i][j]
+			}
+		}
+		total += sum
+		mean := sum / float64(len(members)-1)
+		if mean > bestMean {
+			bestMean = mean
+			cluster.Representative = faceprints[i]
+		}
+	}
+	cluster.Confidence = total / float64(len(members)*(len(members)-1))
+	return cluster
+}
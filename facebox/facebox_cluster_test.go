@@ -0,0 +1,90 @@
+package facebox_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestClusterFaceprints(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/faceprint/compare")
+		var request struct {
+			Target     string   `json:"target"`
+			Faceprints []string `json:"faceprints"`
+		}
+		is.NoErr(json.NewDecoder(r.Body).Decode(&request))
+		is.True(len(request.Faceprints) <= 2)
+		confidences := make([]float64, len(request.Faceprints))
+		for i, faceprint := range request.Faceprints {
+			switch {
+			case faceprint[0] != request.Target[0]:
+				confidences[i] = 0.1
+			case faceprint == "a3" || request.Target == "a3":
+				confidences[i] = 0.7
+			default:
+				confidences[i] = 0.9
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"confidences": confidences,
+		})
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	faceprints := []string{"a1", "b1", "a2", "c1", "a3", "b2"}
+	clusters, err := fb.ClusterFaceprints(context.Background(), faceprints, &facebox.ClusterOptions{
+		BatchSize: 2,
+	})
+	is.NoErr(err)
+	is.Equal(len(clusters), 3)
+	is.Equal(clusters[0].Faceprints, []string{"a1", "a2", "a3"})
+	is.Equal(clusters[0].Representative, "a1")
+	is.True(clusters[0].Confidence > 0.76 && clusters[0].Confidence < 0.77)
+	is.Equal(clusters[1].Faceprints, []string{"b1", "b2"})
+	is.Equal(clusters[1].Confidence, 0.9)
+	is.Equal(clusters[2].Faceprints, []string{"c1"})
+	is.Equal(clusters[2].Representative, "c1")
+	is.Equal(clusters[2].Confidence, 1.0)
+}
+
+func TestClusterFaceprintsError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success": false, "error": "something went wrong"}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	_, err := fb.ClusterFaceprints(context.Background(), []string{"a1", "a2"}, nil)
+	is.True(err != nil)
+	is.Equal(err.Error(), "compare faceprints: facebox: something went wrong")
+}
+
+func TestTeachCluster(t *testing.T) {
+	is := is.New(t)
+	var lock sync.Mutex
+	taught := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/teach")
+		is.Equal(r.FormValue("name"), "John Lennon")
+		lock.Lock()
+		taught[r.FormValue("faceprint")] = r.FormValue("id")
+		lock.Unlock()
+		io.WriteString(w, `{"success": true}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	cluster := facebox.Cluster{Faceprints: []string{"a1", "a2"}}
+	is.NoErr(fb.TeachCluster(cluster, "John Lennon"))
+	is.Equal(len(taught), 2)
+	is.Equal(taught["a1"], facebox.IDFromContentHash("", []byte("a1")))
+}
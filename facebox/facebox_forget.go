@@ -0,0 +1,191 @@
+package facebox
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ForgetOptions control the behaviour of ForgetPerson.
+type ForgetOptions struct {
+	// Known are faces from a client side index, such as a
+	// TeachDirReport or JournalFaces.TaughtFaces. Faces with other
+	// names are ignored.
+	Known []TaughtFace
+	// Probes are images of the person, used to discover IDs with
+	// Similar and to verify that the person is no longer matched.
+	Probes [][]byte
+	// Faceprints are faceprints of the person, used to verify
+	// that the person is no longer matched.
+	Faceprints []string
+	// Remove is called to remove each ID. Defaults to Client.Remove,
+	// but may be set to Journal.Remove so the removals are recorded.
+	Remove func(id string) error
+	// MaxRounds is the maximum number of times to look for more IDs
+	// after removing those already found. Defaults to 10.
+	MaxRounds int
+}
+
+// ForgetRecord is an audit record of a ForgetPerson operation.
+type ForgetRecord struct {
+	// Name is the name of the person that was forgotten.
+	Name string `json:"name"`
+	// Started is when the operation started.
+	Started time.Time `json:"started"`
+	// Finished is when the operation finished.
+	Finished time.Time `json:"finished"`
+	// Removed are the IDs that were removed.
+	Removed []string `json:"removed"`
+	// Failed are the IDs that could not be removed.
+	Failed []AuditError `json:"failed,omitempty"`
+	// LookupFailed are the IDs whose similar faces could not be
+	// looked up, so other IDs of the person may have been missed.
+	LookupFailed []AuditError `json:"lookup_failed,omitempty"`
+	// Checks is the number of probes and faceprints that were
+	// checked after removal.
+	Checks int `json:"checks"`
+	// Remaining are IDs that still matched the person after removal.
+	Remaining []string `json:"remaining,omitempty"`
+	// Verified is true if at least one check was made and none of
+	// them matched the person.
+	Verified bool `json:"verified"`
+}
+
+// ForgetPerson removes every example of the named person, for example
+// to honour a request for erasure.
+// Facebox cannot list the IDs taught for a name, so they are discovered
+// from the known faces and the probe images, and by repeatedly calling
+// SimilarID and Similar until no more are found. Afterwards the probes
+// and faceprints are checked to verify the person is no longer matched.
+// The returned ForgetRecord should be kept as an audit record; it is
+// returned along with an error if any IDs could not be removed or
+// looked up, or the person could not be verified as forgotten, either
+// because they are still matched or because there were no probes or
+// faceprints to check.
+func (c *Client) ForgetPerson(ctx context.Context, name string, options *ForgetOptions) (*ForgetRecord, error) {
+	if name == "" {
+		return nil, errors.New("name can not be empty")
+	}
+	if options == nil {
+		options = &ForgetOptions{}
+	}
+	if len(options.Known) == 0 && len(options.Probes) == 0 && len(options.Faceprints) == 0 {
+		return nil, errors.New("facebox: Known, Probes or Faceprints are required to find the person")
+	}
+	remove := options.Remove
+	if remove == nil {
+		remove = c.Remove
+	}
+	maxRounds := options.MaxRounds
+	if maxRounds < 1 {
+		maxRounds = 10
+	}
+	record := &ForgetRecord{
+		Name:    name,
+		Started: time.Now(),
+	}
+	// the record is finished however the operation ends, so failed
+	// operations still leave a complete audit record
+	defer func() {
+		record.Finished = time.Now()
+	}()
+	seen := make(map[string]bool)
+	var pending []string
+	found := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			pending = append(pending, id)
+		}
+	}
+	for _, face := range options.Known {
+		if face.Name == name {
+			found(face.ID)
+		}
+	}
+	for round := 0; round < maxRounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return record, err
+		}
+		for _, probe := range options.Probes {
+			similars, err := c.Similar(bytes.NewReader(probe))
+			if err != nil {
+				return record, errors.Wrap(err, "similar")
+			}
+			for _, similar := range similars {
+				if similar.Name == name {
+					found(similar.ID)
+				}
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		for len(pending) > 0 {
+			if err := ctx.Err(); err != nil {
+				return record, err
+			}
+			id := pending[0]
+			pending = pending[1:]
+			similars, err := c.SimilarID(id)
+			if err != nil {
+				record.LookupFailed = append(record.LookupFailed, AuditError{ID: id, Error: err.Error()})
+			}
+			for _, similar := range similars {
+				if similar.Name == name {
+					found(similar.ID)
+				}
+			}
+			if err := remove(id); err != nil {
+				record.Failed = append(record.Failed, AuditError{ID: id, Error: err.Error()})
+				continue
+			}
+			record.Removed = append(record.Removed, id)
+		}
+	}
+	remaining := make(map[string]bool)
+	for _, probe := range options.Probes {
+		faces, err := c.Check(bytes.NewReader(probe))
+		if err != nil {
+			return record, errors.Wrap(err, "check")
+		}
+		record.Checks++
+		for _, face := range faces {
+			if face.Matched && face.Name == name {
+				remaining[face.ID] = true
+			}
+		}
+	}
+	if len(options.Faceprints) > 0 {
+		faces, err := c.CheckFaceprints(options.Faceprints)
+		if err != nil {
+			return record, errors.Wrap(err, "check faceprints")
+		}
+		record.Checks += len(options.Faceprints)
+		for _, face := range faces {
+			if face.Matched && face.Name == name {
+				remaining[face.ID] = true
+			}
+		}
+	}
+	for id := range remaining {
+		record.Remaining = append(record.Remaining, id)
+	}
+	sort.Strings(record.Remaining)
+	record.Verified = record.Checks > 0 && len(record.Remaining) == 0
+	if len(record.Failed) > 0 {
+		return record, errors.Errorf("facebox: failed to remove %d faces", len(record.Failed))
+	}
+	if len(record.LookupFailed) > 0 {
+		return record, errors.Errorf("facebox: failed to look up similar faces for %d faces", len(record.LookupFailed))
+	}
+	if len(record.Remaining) > 0 {
+		return record, errors.Errorf("facebox: %s is still matched", name)
+	}
+	if record.Checks == 0 {
+		return record, errors.New("facebox: not verified; Probes or Faceprints are required to check the person is forgotten")
+	}
+	return record, nil
+}
@@ -0,0 +1,138 @@
+package facebox_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestForgetPerson(t *testing.T) {
+	is := is.New(t)
+	box, srv := newFakeFacebox()
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	for _, id := range []string{"john-1", "john-2", "john-3", "john-4", "john-5"} {
+		is.NoErr(fb.TeachFaceprint(id, id+".jpg", "John Lennon"))
+	}
+	is.NoErr(fb.TeachFaceprint("ringo-1", "ringo-1.jpg", "Ringo Starr"))
+	var removed []string
+	record, err := fb.ForgetPerson(context.Background(), "John Lennon", &facebox.ForgetOptions{
+		Known: []facebox.TaughtFace{
+			{ID: "john-1.jpg", Name: "John Lennon"},
+			{ID: "ringo-1.jpg", Name: "Ringo Starr"},
+		},
+		Faceprints: []string{"john-new"},
+		Remove: func(id string) error {
+			removed = append(removed, id)
+			return fb.Remove(id)
+		},
+	})
+	is.NoErr(err)
+	is.Equal(record.Name, "John Lennon")
+	is.Equal(len(record.Removed), 5)
+	is.Equal(removed, record.Removed)
+	is.Equal(record.Removed[0], "john-1.jpg")
+	is.Equal(len(record.Failed), 0)
+	is.Equal(record.Checks, 1)
+	is.Equal(record.Verified, true)
+	is.True(!record.Finished.Before(record.Started))
+	is.Equal(box.count(), 1)
+}
+
+func TestForgetPersonProbes(t *testing.T) {
+	is := is.New(t)
+	box, srv := newFakeFacebox()
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	for _, id := range []string{"john-1", "john-2", "john-3"} {
+		is.NoErr(fb.TeachFaceprint(id, id+".jpg", "John Lennon"))
+	}
+	// taught under a different name, so it will not be discovered
+	is.NoErr(fb.TeachFaceprint("john-4", "john-4.jpg", "John"))
+	record, err := fb.ForgetPerson(context.Background(), "John Lennon", &facebox.ForgetOptions{
+		Probes: [][]byte{[]byte("john-probe")},
+	})
+	is.NoErr(err)
+	is.Equal(len(record.Removed), 3)
+	is.Equal(record.Verified, true)
+	is.Equal(box.count(), 1)
+
+	record, err = fb.ForgetPerson(context.Background(), "John", &facebox.ForgetOptions{
+		Known: []facebox.TaughtFace{{ID: "ghost.jpg", Name: "John"}},
+	})
+	is.Equal(err.Error(), "facebox: failed to remove 1 faces")
+	is.Equal(record.Failed[0].ID, "ghost.jpg")
+	is.Equal(record.Verified, false)
+}
+
+func TestForgetPersonSimilarError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/similar")
+		io.WriteString(w, `{"success": false, "error": "box is busy"}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	record, err := fb.ForgetPerson(context.Background(), "John Lennon", &facebox.ForgetOptions{
+		Probes: [][]byte{[]byte(`(pretend this is image data)`)},
+	})
+	is.Equal(err.Error(), "similar: facebox: box is busy")
+	is.Equal(record.Name, "John Lennon")
+	is.True(!record.Finished.IsZero())
+	is.True(!record.Finished.Before(record.Started))
+	is.Equal(record.Verified, false)
+}
+
+func TestForgetPersonUnverified(t *testing.T) {
+	is := is.New(t)
+	box, srv := newFakeFacebox()
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	is.NoErr(fb.TeachFaceprint("john-1", "john-1.jpg", "John Lennon"))
+
+	record, err := fb.ForgetPerson(context.Background(), "John Lennon", nil)
+	is.Equal(err.Error(), "facebox: Known, Probes or Faceprints are required to find the person")
+	is.Equal(record, nil)
+	is.Equal(box.count(), 1)
+
+	record, err = fb.ForgetPerson(context.Background(), "John Lennon", &facebox.ForgetOptions{
+		Known: []facebox.TaughtFace{{ID: "john-1.jpg", Name: "John Lennon"}},
+	})
+	is.Equal(err.Error(), "facebox: not verified; Probes or Faceprints are required to check the person is forgotten")
+	is.Equal(record.Removed, []string{"john-1.jpg"})
+	is.Equal(record.Verified, false)
+	is.Equal(box.count(), 0)
+}
+
+func TestForgetPersonSimilarIDError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/facebox/similar":
+			io.WriteString(w, `{"success": false, "error": "box is busy"}`)
+		case r.URL.Path == "/facebox/teach/john-1.jpg":
+			io.WriteString(w, `{"success": true}`)
+		case r.URL.Path == "/facebox/faceprint/check":
+			io.WriteString(w, `{"success": true, "faceprints": [{"matched": false}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	record, err := fb.ForgetPerson(context.Background(), "John Lennon", &facebox.ForgetOptions{
+		Known:      []facebox.TaughtFace{{ID: "john-1.jpg", Name: "John Lennon"}},
+		Faceprints: []string{"john-new"},
+	})
+	is.Equal(err.Error(), "facebox: failed to look up similar faces for 1 faces")
+	is.Equal(record.Removed, []string{"john-1.jpg"})
+	is.Equal(len(record.LookupFailed), 1)
+	is.Equal(record.LookupFailed[0].ID, "john-1.jpg")
+	is.Equal(record.LookupFailed[0].Error, "facebox: box is busy")
+	is.Equal(record.Verified, true)
+}
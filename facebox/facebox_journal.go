@@ -0,0 +1,344 @@
+package facebox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JournalOp is the kind of operation recorded in a JournalEntry.
+type JournalOp string
+
+const (
+	// JournalTeach records a Teach operation.
+	JournalTeach JournalOp = "teach"
+	// JournalRemove records a Remove operation.
+	JournalRemove JournalOp = "remove"
+	// JournalRename records a Rename operation.
+	JournalRename JournalOp = "rename"
+	// JournalRenameAll records a RenameAll operation.
+	JournalRenameAll JournalOp = "rename_all"
+)
+
+// JournalEntry is a single operation recorded in a Journal.
+type JournalEntry struct {
+	// Time is when the operation was performed.
+	Time time.Time `json:"time"`
+	// Op is the kind of operation.
+	Op JournalOp `json:"op"`
+	// ID is the ID of the face. It is empty for JournalRenameAll.
+	ID string `json:"id,omitempty"`
+	// Name is the name of the person, or the new name for
+	// JournalRename and JournalRenameAll.
+	Name string `json:"name,omitempty"`
+	// OldName is the old name for JournalRenameAll.
+	OldName string `json:"old_name,omitempty"`
+	// Faceprint is the faceprint that was taught, if known.
+	Faceprint string `json:"faceprint,omitempty"`
+	// URL is the URL of the image that was taught, if any.
+	URL string `json:"url,omitempty"`
+	// SHA1 is the hash of the image data that was taught, if
+	// the image was not taught by URL or faceprint.
+	SHA1 string `json:"sha1,omitempty"`
+}
+
+// Journal records every change made to facebox through it in an
+// append-only log of JSON lines, so that the faces can be taught to
+// a fresh box with ReplayJournal, and so the list of taught faces can
+// be read with ReadJournalFaces.
+// Images are recorded by their faceprint where possible, so images
+// containing anything other than a single face cannot be replayed
+// unless they were taught by URL.
+type Journal struct {
+	client *Client
+
+	lock   sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJournal makes a new Journal that records changes made to the
+// client in w.
+func NewJournal(client *Client, w io.Writer) *Journal {
+	return &Journal{
+		client: client,
+		w:      w,
+	}
+}
+
+// OpenJournal opens (or creates) the journal file at path for
+// appending.
+// Clients must call Close.
+func OpenJournal(client *Client, path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, errors.Wrap(err, "open journal")
+	}
+	j := NewJournal(client, f)
+	j.closer = f
+	return j, nil
+}
+
+// Close closes the journal file if it was opened with OpenJournal.
+func (j *Journal) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
+
+// record appends the entry to the journal.
+func (j *Journal) record(entry JournalEntry) error {
+	entry.Time = time.Now()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "encode journal entry")
+	}
+	b = append(b, '\n')
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if _, err := j.w.Write(b); err != nil {
+		return errors.Wrap(err, "write journal")
+	}
+	return nil
+}
+
+// Teach teaches facebox the face in the io.Reader and records it
+// in the journal.
+// See Client.Teach for more information.
+func (j *Journal) Teach(image io.Reader, id, name string) error {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return errors.Wrap(err, "read image")
+	}
+	faceprint, err := j.client.teachFaceprintOf(id, name, func(options *CheckOptions) ([]Face, error) {
+		return j.client.CheckWithOptions(bytes.NewReader(data), options)
+	}, func() error {
+		return j.client.Teach(bytes.NewReader(data), id, name)
+	})
+	if err != nil {
+		return err
+	}
+	entry := JournalEntry{Op: JournalTeach, ID: id, Name: name, Faceprint: faceprint}
+	if faceprint == "" {
+		sum := sha1.Sum(data)
+		entry.SHA1 = hex.EncodeToString(sum[:])
+	}
+	return j.record(entry)
+}
+
+// TeachURL teaches facebox the face in the image at the specified URL
+// and records it in the journal.
+// See Client.Teach for more information.
+func (j *Journal) TeachURL(imageURL *url.URL, id, name string) error {
+	faceprint, err := j.client.teachFaceprintOf(id, name, func(options *CheckOptions) ([]Face, error) {
+		return j.client.CheckURLWithOptions(imageURL, options)
+	}, func() error {
+		return j.client.TeachURL(imageURL, id, name)
+	})
+	if err != nil {
+		return err
+	}
+	return j.record(JournalEntry{Op: JournalTeach, ID: id, Name: name, Faceprint: faceprint, URL: imageURL.String()})
+}
+
+// TeachBase64 teaches facebox the face in the Base64 encoded image and
+// records it in the journal.
+// See Client.Teach for more information.
+func (j *Journal) TeachBase64(data, id, name string) error {
+	faceprint, err := j.client.teachFaceprintOf(id, name, func(options *CheckOptions) ([]Face, error) {
+		return j.client.CheckBase64WithOptions(data, options)
+	}, func() error {
+		return j.client.TeachBase64(data, id, name)
+	})
+	if err != nil {
+		return err
+	}
+	entry := JournalEntry{Op: JournalTeach, ID: id, Name: name, Faceprint: faceprint}
+	if faceprint == "" {
+		sum := sha1.Sum([]byte(data))
+		entry.SHA1 = hex.EncodeToString(sum[:])
+	}
+	return j.record(entry)
+}
+
+// TeachFaceprint teaches facebox the face represented by the faceprint
+// and records it in the journal.
+// See Client.Teach for more information.
+func (j *Journal) TeachFaceprint(faceprint, id, name string) error {
+	if err := j.client.TeachFaceprint(faceprint, id, name); err != nil {
+		return err
+	}
+	return j.record(JournalEntry{Op: JournalTeach, ID: id, Name: name, Faceprint: faceprint})
+}
+
+// Remove makes facebox forget a face and records it in the journal.
+func (j *Journal) Remove(id string) error {
+	if err := j.client.Remove(id); err != nil {
+		return err
+	}
+	return j.record(JournalEntry{Op: JournalRemove, ID: id})
+}
+
+// Rename changes the name for a given face and records it in
+// the journal.
+func (j *Journal) Rename(id, name string) error {
+	if err := j.client.Rename(id, name); err != nil {
+		return err
+	}
+	return j.record(JournalEntry{Op: JournalRename, ID: id, Name: name})
+}
+
+// RenameAll changes the name for all the faces that match a given name
+// and records it in the journal.
+func (j *Journal) RenameAll(oldName, newName string) error {
+	if err := j.client.RenameAll(oldName, newName); err != nil {
+		return err
+	}
+	return j.record(JournalEntry{Op: JournalRenameAll, Name: newName, OldName: oldName})
+}
+
+// ReadJournal reads all the entries in the journal.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16*1024*1024)
+	line := 0
+	for s.Scan() {
+		line++
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(s.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		entries = append(entries, entry)
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "read journal")
+	}
+	return entries, nil
+}
+
+// JournalFace is a face that the journal says is taught.
+type JournalFace struct {
+	TaughtFace
+	// Faceprint is the faceprint that was taught, if known.
+	Faceprint string `json:"faceprint,omitempty"`
+	// URL is the URL of the image that was taught, if any.
+	URL string `json:"url,omitempty"`
+}
+
+// JournalFaces are the faces that the journal says are taught.
+type JournalFaces []JournalFace
+
+// TaughtFaces gets the faces as TaughtFaces, for use with Audit
+// and ForgetPerson.
+func (faces JournalFaces) TaughtFaces() []TaughtFace {
+	taught := make([]TaughtFace, len(faces))
+	for i, face := range faces {
+		taught[i] = face.TaughtFace
+	}
+	return taught
+}
+
+// ReadJournalFaces reads the journal and works out which faces are
+// currently taught, ordered by ID.
+func ReadJournalFaces(r io.Reader) (JournalFaces, error) {
+	entries, err := ReadJournal(r)
+	if err != nil {
+		return nil, err
+	}
+	return journalFaces(entries), nil
+}
+
+// journalFaces applies the entries in order, returning the faces
+// that are taught at the end, ordered by ID.
+func journalFaces(entries []JournalEntry) JournalFaces {
+	faces := make(map[string]JournalFace)
+	for _, entry := range entries {
+		switch entry.Op {
+		case JournalTeach:
+			faces[entry.ID] = JournalFace{
+				TaughtFace: TaughtFace{ID: entry.ID, Name: entry.Name},
+				Faceprint:  entry.Faceprint,
+				URL:        entry.URL,
+			}
+		case JournalRemove:
+			delete(faces, entry.ID)
+		case JournalRename:
+			if face, ok := faces[entry.ID]; ok {
+				face.Name = entry.Name
+				faces[entry.ID] = face
+			}
+		case JournalRenameAll:
+			for id, face := range faces {
+				if face.Name == entry.OldName {
+					face.Name = entry.Name
+					faces[id] = face
+				}
+			}
+		}
+	}
+	list := make(JournalFaces, 0, len(faces))
+	for _, face := range faces {
+		list = append(list, face)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// ReplayReport describes the outcome of ReplayJournal.
+type ReplayReport struct {
+	// Taught is the number of faces that were taught.
+	Taught int `json:"taught"`
+	// Skipped are the faces that could not be replayed because
+	// neither their faceprint nor URL was recorded.
+	Skipped []JournalFace `json:"skipped"`
+}
+
+// ReplayJournal teaches the faces in the journal to the client,
+// usually a fresh box whose state has been lost.
+// Only the faces that are taught at the end of the journal are
+// replayed, with their latest names.
+func ReplayJournal(r io.Reader, client *Client) (*ReplayReport, error) {
+	faces, err := ReadJournalFaces(r)
+	if err != nil {
+		return nil, err
+	}
+	report := &ReplayReport{}
+	for _, face := range faces {
+		switch {
+		case face.Faceprint != "":
+			err = client.TeachFaceprint(face.Faceprint, face.ID, face.Name)
+		case face.URL != "":
+			var imageURL *url.URL
+			imageURL, err = url.Parse(face.URL)
+			if err == nil {
+				err = client.TeachURL(imageURL, face.ID, face.Name)
+			}
+		default:
+			report.Skipped = append(report.Skipped, face)
+			continue
+		}
+		if err != nil {
+			return report, errors.Wrapf(err, "teach %s", face.ID)
+		}
+		report.Taught++
+	}
+	return report, nil
+}
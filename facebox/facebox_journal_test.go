@@ -0,0 +1,92 @@
+package facebox_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestJournal(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "facebox-journal")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.jsonl")
+
+	box, srv := newFakeFacebox()
+	defer srv.Close()
+	journal, err := facebox.OpenJournal(facebox.New(srv.URL), path)
+	is.NoErr(err)
+	is.NoErr(journal.Teach(strings.NewReader("john1"), "john1.jpg", "John"))
+	is.NoErr(journal.TeachBase64("john2", "john2.jpg", "John"))
+	is.NoErr(journal.TeachFaceprint("ringo1", "ringo1.jpg", "Ringo"))
+	is.NoErr(journal.TeachFaceprint("paul1", "paul1.jpg", "Paul"))
+	is.NoErr(journal.Close())
+
+	// reopening appends
+	journal, err = facebox.OpenJournal(facebox.New(srv.URL), path)
+	is.NoErr(err)
+	defer journal.Close()
+	is.NoErr(journal.RenameAll("John", "John Lennon"))
+	is.NoErr(journal.Rename("ringo1.jpg", "Ringo Starr"))
+	is.NoErr(journal.Remove("paul1.jpg"))
+	is.Equal(box.count(), 3)
+
+	f, err := os.Open(path)
+	is.NoErr(err)
+	entries, err := facebox.ReadJournal(f)
+	f.Close()
+	is.NoErr(err)
+	is.Equal(len(entries), 7)
+	is.Equal(entries[0].Op, facebox.JournalTeach)
+	is.Equal(entries[0].Faceprint, "john1")
+	is.Equal(entries[4].Op, facebox.JournalRenameAll)
+	is.Equal(entries[4].OldName, "John")
+	is.True(!entries[6].Time.IsZero())
+
+	f, err = os.Open(path)
+	is.NoErr(err)
+	faces, err := facebox.ReadJournalFaces(f)
+	f.Close()
+	is.NoErr(err)
+	is.Equal(len(faces), 3)
+	is.Equal(faces[0].TaughtFace, facebox.TaughtFace{ID: "john1.jpg", Name: "John Lennon"})
+	is.Equal(faces[1].TaughtFace, facebox.TaughtFace{ID: "john2.jpg", Name: "John Lennon"})
+	is.Equal(faces[2].TaughtFace, facebox.TaughtFace{ID: "ringo1.jpg", Name: "Ringo Starr"})
+	taught := faces.TaughtFaces()
+	is.Equal(len(taught), 3)
+	is.Equal(taught[2], facebox.TaughtFace{ID: "ringo1.jpg", Name: "Ringo Starr"})
+
+	fresh, freshSrv := newFakeFacebox()
+	defer freshSrv.Close()
+	f, err = os.Open(path)
+	is.NoErr(err)
+	report, err := facebox.ReplayJournal(f, facebox.New(freshSrv.URL))
+	f.Close()
+	is.NoErr(err)
+	is.Equal(report.Taught, 3)
+	is.Equal(len(report.Skipped), 0)
+	is.Equal(fresh.count(), 3)
+	is.Equal(fresh.names["ringo1.jpg"], "Ringo Starr")
+	is.Equal(fresh.faces["ringo1.jpg"], "ringo1")
+}
+
+func TestReplayJournalSkipped(t *testing.T) {
+	is := is.New(t)
+	journal := `{"op":"teach","id":"group.jpg","name":"John","sha1":"abc"}
+{"op":"teach","id":"url.jpg","name":"John","url":"https://test.machinebox.io/image1.png"}
+`
+	box, srv := newFakeFacebox()
+	defer srv.Close()
+	report, err := facebox.ReplayJournal(strings.NewReader(journal), facebox.New(srv.URL))
+	is.NoErr(err)
+	is.Equal(report.Taught, 1)
+	is.Equal(len(report.Skipped), 1)
+	is.Equal(report.Skipped[0].ID, "group.jpg")
+	is.Equal(box.names["url.jpg"], "John")
+}
@@ -0,0 +1,296 @@
+package facebox
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// PresenceEventType is the kind of PresenceEvent.
+type PresenceEventType string
+
+const (
+	// PresenceEnter indicates that a person has arrived.
+	PresenceEnter PresenceEventType = "enter"
+	// PresenceExit indicates that a person has left.
+	PresenceExit PresenceEventType = "exit"
+)
+
+// PresenceEvent describes a person arriving or leaving.
+type PresenceEvent struct {
+	// Type is the kind of event.
+	Type PresenceEventType `json:"type"`
+	// TrackID uniquely identifies the person within the tracker.
+	TrackID int `json:"track_id"`
+	// Name is the name of the person, or empty if the face
+	// was never matched.
+	Name string `json:"name"`
+	// Time is when the person was first seen (for PresenceEnter)
+	// or last seen (for PresenceExit).
+	Time time.Time `json:"time"`
+	// Dwell is how long the person was present. It is only set
+	// for PresenceExit.
+	Dwell time.Duration `json:"dwell,omitempty"`
+	// Face is the most recent sighting of the face.
+	Face Face `json:"face"`
+}
+
+// PresenceOptions control the behaviour of a PresenceTracker.
+type PresenceOptions struct {
+	// EnterFrames is the number of frames a face must be seen in
+	// before a PresenceEnter event is emitted. Defaults to 2.
+	EnterFrames int
+	// ExitAfter is how long a face must be missing before a
+	// PresenceExit event is emitted. Defaults to 2 seconds.
+	ExitAfter time.Duration
+	// MinOverlap is the minimum intersection over union of the
+	// rects for an unmatched face to be associated with a face in
+	// a previous frame. Defaults to 0.3.
+	MinOverlap float64
+	// IgnoreUnknown ignores faces that are not matched.
+	IgnoreUnknown bool
+	// Buffer is the size of the events channel. Defaults to 100.
+	// Events that do not fit are held by the tracker until they
+	// are read.
+	Buffer int
+}
+
+// PresenceTracker turns a sequence of frames into events describing
+// people arriving and leaving.
+// Faces are associated across frames by name, or by the overlap of
+// their rects for faces that are not matched.
+type PresenceTracker struct {
+	client  *Client
+	options PresenceOptions
+	events  chan PresenceEvent
+
+	lock    sync.Mutex
+	ready   *sync.Cond // signalled when pending or closed changes
+	nextID  int
+	tracks  []*presenceTrack
+	pending []PresenceEvent
+	closed  bool
+}
+
+type presenceTrack struct {
+	id        int
+	name      string
+	face      Face
+	firstSeen time.Time
+	lastSeen  time.Time
+	frames    int
+	entered   bool
+}
+
+// NewPresenceTracker makes a new PresenceTracker that uses the client
+// to check frames.
+// Callers must read from Events, and call Close when finished.
+func NewPresenceTracker(client *Client, options *PresenceOptions) *PresenceTracker {
+	var o PresenceOptions
+	if options != nil {
+		o = *options
+	}
+	if o.EnterFrames < 1 {
+		o.EnterFrames = 2
+	}
+	if o.ExitAfter <= 0 {
+		o.ExitAfter = 2 * time.Second
+	}
+	if o.MinOverlap <= 0 {
+		o.MinOverlap = 0.3
+	}
+	if o.Buffer < 1 {
+		o.Buffer = 100
+	}
+	t := &PresenceTracker{
+		client:  client,
+		options: o,
+		events:  make(chan PresenceEvent, o.Buffer),
+	}
+	t.ready = sync.NewCond(&t.lock)
+	go t.send()
+	return t
+}
+
+// send delivers pending events to the Events channel, and closes
+// it once the tracker is closed and every event has been sent.
+// Events are sent without holding the lock so that a slow reader
+// never blocks Track or Close.
+func (t *PresenceTracker) send() {
+	for {
+		t.lock.Lock()
+		for len(t.pending) == 0 && !t.closed {
+			t.ready.Wait()
+		}
+		if len(t.pending) == 0 {
+			t.lock.Unlock()
+			close(t.events)
+			return
+		}
+		event := t.pending[0]
+		t.pending[0] = PresenceEvent{}
+		t.pending = t.pending[1:]
+		t.lock.Unlock()
+		t.events <- event
+	}
+}
+
+// queue adds the event to the pending events.
+// Callers must hold the lock.
+func (t *PresenceTracker) queue(event PresenceEvent) {
+	t.pending = append(t.pending, event)
+	t.ready.Signal()
+}
+
+// Events gets the channel on which events are sent.
+// The channel is closed by Close.
+func (t *PresenceTracker) Events() <-chan PresenceEvent {
+	return t.events
+}
+
+// Check checks the frame in the io.Reader for faces and tracks them.
+// Frames must be provided in time order.
+func (t *PresenceTracker) Check(frame io.Reader, timestamp time.Time) error {
+	faces, err := t.client.Check(frame)
+	if err != nil {
+		return err
+	}
+	t.Track(faces, timestamp)
+	return nil
+}
+
+// Track tracks faces that have already been checked.
+// Frames must be provided in time order.
+func (t *PresenceTracker) Track(faces []Face, timestamp time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	seen := make(map[*presenceTrack]bool)
+	for _, face := range faces {
+		if !face.Matched && t.options.IgnoreUnknown {
+			continue
+		}
+		track := t.associate(face, seen)
+		if track == nil {
+			t.nextID++
+			track = &presenceTrack{id: t.nextID, firstSeen: timestamp}
+			t.tracks = append(t.tracks, track)
+		}
+		seen[track] = true
+		if face.Matched {
+			track.name = face.Name
+		}
+		track.face = face
+		track.lastSeen = timestamp
+		track.frames++
+		if !track.entered && track.frames >= t.options.EnterFrames {
+			track.entered = true
+			t.queue(PresenceEvent{
+				Type:    PresenceEnter,
+				TrackID: track.id,
+				Name:    track.name,
+				Time:    track.firstSeen,
+				Face:    track.face,
+			})
+		}
+	}
+	tracks := t.tracks[:0]
+	for _, track := range t.tracks {
+		if seen[track] || timestamp.Sub(track.lastSeen) < t.options.ExitAfter {
+			tracks = append(tracks, track)
+			continue
+		}
+		t.exit(track)
+	}
+	t.tracks = tracks
+}
+
+// associate finds the track the face belongs to, or nil if it
+// is a new face. Tracks that have already been seen in this
+// frame are not considered.
+// Callers must hold the lock.
+func (t *PresenceTracker) associate(face Face, seen map[*presenceTrack]bool) *presenceTrack {
+	if face.Matched {
+		for _, track := range t.tracks {
+			if !seen[track] && track.name == face.Name {
+				return track
+			}
+		}
+	}
+	var best *presenceTrack
+	bestOverlap := t.options.MinOverlap
+	for _, track := range t.tracks {
+		if seen[track] {
+			continue
+		}
+		if face.Matched && track.name != "" {
+			// a different known person
+			continue
+		}
+		if overlap := iou(face.Rect, track.face.Rect); overlap >= bestOverlap {
+			best, bestOverlap = track, overlap
+		}
+	}
+	return best
+}
+
+// exit queues the PresenceExit event for the track, if it entered.
+// Callers must hold the lock.
+func (t *PresenceTracker) exit(track *presenceTrack) {
+	if !track.entered {
+		// flicker; never announced
+		return
+	}
+	t.queue(PresenceEvent{
+		Type:    PresenceExit,
+		TrackID: track.id,
+		Name:    track.name,
+		Time:    track.lastSeen,
+		Dwell:   track.lastSeen.Sub(track.firstSeen),
+		Face:    track.face,
+	})
+}
+
+// Close sends PresenceExit events for everybody still present, and
+// closes the Events channel once every event has been read.
+func (t *PresenceTracker) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, track := range t.tracks {
+		t.exit(track)
+	}
+	t.tracks = nil
+	t.ready.Signal()
+}
+
+// iou gets the intersection over union of two rects.
+func iou(a, b Rect) float64 {
+	left, top := maxInt(a.Left, b.Left), maxInt(a.Top, b.Top)
+	right, bottom := minInt(a.Left+a.Width, b.Left+b.Width), minInt(a.Top+a.Height, b.Top+b.Height)
+	if right <= left || bottom <= top {
+		return 0
+	}
+	intersection := (right - left) * (bottom - top)
+	union := a.area() + b.area() - intersection
+	return float64(intersection) / float64(union)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
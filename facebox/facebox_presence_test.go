@@ -0,0 +1,125 @@
+package facebox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	is := is.New(t)
+	tracker := facebox.NewPresenceTracker(nil, &facebox.PresenceOptions{
+		ExitAfter: 2 * time.Second,
+	})
+	john := facebox.Face{Rect: facebox.Rect{Left: 0, Top: 0, Width: 100, Height: 100}, Matched: true, Name: "John Lennon", Confidence: 0.8}
+	stranger := facebox.Face{Rect: facebox.Rect{Left: 300, Top: 0, Width: 100, Height: 100}}
+	moved := stranger
+	moved.Rect.Left = 320
+	flicker := facebox.Face{Rect: facebox.Rect{Left: 600, Top: 600, Width: 50, Height: 50}}
+	start := time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	tracker.Track([]facebox.Face{john, stranger}, at(0))
+	tracker.Track([]facebox.Face{john, moved, flicker}, at(1))
+	tracker.Track([]facebox.Face{john}, at(2))
+	tracker.Track(nil, at(3))
+	tracker.Track(nil, at(4))
+	tracker.Track(nil, at(5))
+	tracker.Close()
+
+	var events []facebox.PresenceEvent
+	for event := range tracker.Events() {
+		events = append(events, event)
+	}
+	is.Equal(len(events), 4)
+
+	is.Equal(events[0].Type, facebox.PresenceEnter)
+	is.Equal(events[0].Name, "John Lennon")
+	is.Equal(events[0].Time, at(0))
+	is.Equal(events[1].Type, facebox.PresenceEnter)
+	is.Equal(events[1].Name, "")
+	is.Equal(events[1].Face.Rect.Left, 320)
+
+	is.Equal(events[2].Type, facebox.PresenceExit)
+	is.Equal(events[2].TrackID, events[1].TrackID)
+	is.Equal(events[2].Time, at(1))
+	is.Equal(events[2].Dwell, time.Second)
+
+	is.Equal(events[3].Type, facebox.PresenceExit)
+	is.Equal(events[3].Name, "John Lennon")
+	is.Equal(events[3].Time, at(2))
+	is.Equal(events[3].Dwell, 2*time.Second)
+}
+
+func TestPresenceTrackerCheck(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		io.WriteString(w, `{
+			"success": true,
+			"faces": [
+				{
+					"rect": { "top": 0, "left": 0, "width": 120, "height": 120 },
+					"id": "file1.jpg",
+					"name": "John Lennon",
+					"matched": true,
+					"confidence": 0.8
+				}
+			]
+		}`)
+	}))
+	defer srv.Close()
+	tracker := facebox.NewPresenceTracker(facebox.New(srv.URL), &facebox.PresenceOptions{
+		EnterFrames: 1,
+	})
+	now := time.Now()
+	is.NoErr(tracker.Check(strings.NewReader(`(pretend this is image data)`), now))
+	tracker.Close()
+	event := <-tracker.Events()
+	is.Equal(event.Type, facebox.PresenceEnter)
+	is.Equal(event.Name, "John Lennon")
+	event = <-tracker.Events()
+	is.Equal(event.Type, facebox.PresenceExit)
+	is.Equal(event.Dwell, time.Duration(0))
+	_, ok := <-tracker.Events()
+	is.Equal(ok, false)
+}
+
+func TestPresenceTrackerUnreadEvents(t *testing.T) {
+	is := is.New(t)
+	tracker := facebox.NewPresenceTracker(nil, &facebox.PresenceOptions{
+		EnterFrames: 1,
+		ExitAfter:   time.Second,
+		Buffer:      1,
+	})
+	start := time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			// a different person every two seconds, so each one
+			// enters and then exits
+			face := facebox.Face{Matched: true, Name: strings.Repeat("x", i+1)}
+			tracker.Track([]facebox.Face{face}, start.Add(time.Duration(2*i)*time.Second))
+		}
+		tracker.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Track or Close blocked on unread events")
+	}
+	var events int
+	for range tracker.Events() {
+		events++
+	}
+	is.Equal(events, 400)
+}
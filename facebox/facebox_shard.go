@@ -0,0 +1,579 @@
+package facebox
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// shardReplicas is the number of points each shard has on the
+// consistent hashing ring.
+const shardReplicas = 100
+
+// ShardedClient spreads people across many facebox instances.
+// Each person is owned by a single shard, chosen by consistent hashing
+// of their name; Teach, Remove, Rename and RenameAll are sent to the
+// owning shard, while Check, Similar and Similars are sent to every
+// shard and the results are merged.
+//
+// The ShardedClient keeps an index of the faceprint of every face
+// taught through it, which it uses to move faces between shards when
+// they are renamed or when shards are added. Use SaveIndex and
+// LoadIndex to persist the index.
+type ShardedClient struct {
+	// changes serializes changes to the shards and index, and is held
+	// while the boxes are called, so a change sees a consistent ring
+	// without blocking Check, Similar and Similars.
+	changes sync.Mutex
+
+	// lock protects the fields below, and is only held briefly.
+	// Writers must also hold changes.
+	lock   sync.RWMutex
+	shards []*Client
+	ring   []shardPoint
+	index  map[string]ShardIndexEntry
+}
+
+type shardPoint struct {
+	hash  uint32
+	shard *Client
+}
+
+// ShardIndexEntry describes a face taught through a ShardedClient.
+type ShardIndexEntry struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Faceprint string `json:"faceprint,omitempty"`
+}
+
+// NewSharded makes a new ShardedClient that spreads people across
+// the specified clients.
+func NewSharded(shards ...*Client) *ShardedClient {
+	s := &ShardedClient{
+		index: make(map[string]ShardIndexEntry),
+	}
+	for _, shard := range shards {
+		s.shards = append(s.shards, shard)
+		s.ring = addToRing(s.ring, shard)
+	}
+	return s
+}
+
+// addToRing gets a new consistent hashing ring with the points of
+// the shard added to ring.
+func addToRing(ring []shardPoint, shard *Client) []shardPoint {
+	added := make([]shardPoint, len(ring), len(ring)+shardReplicas)
+	copy(added, ring)
+	for i := 0; i < shardReplicas; i++ {
+		added = append(added, shardPoint{
+			hash:  shardHash(shard.addr + "#" + strconv.Itoa(i)),
+			shard: shard,
+		})
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].hash < added[j].hash
+	})
+	return added
+}
+
+// shardHash hashes s onto the ring. SHA-1 is used because it mixes
+// well; similar strings such as the points of a shard must not
+// cluster together.
+func shardHash(s string) uint32 {
+	sum := sha1.Sum([]byte(s))
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// Shard gets the client that owns the named person.
+func (s *ShardedClient) Shard(name string) *Client {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.shard(name)
+}
+
+// shard gets the client that owns the named person.
+// Callers must hold the lock or changes.
+func (s *ShardedClient) shard(name string) *Client {
+	return ringShard(s.ring, name)
+}
+
+// ringShard gets the client on the ring that owns the named person.
+func ringShard(ring []shardPoint, name string) *Client {
+	if len(ring) == 0 {
+		return nil
+	}
+	h := shardHash(name)
+	i := sort.Search(len(ring), func(i int) bool {
+		return ring[i].hash >= h
+	})
+	if i == len(ring) {
+		i = 0
+	}
+	return ring[i].shard
+}
+
+// Index gets the faces that have been taught through the ShardedClient,
+// ordered by ID.
+func (s *ShardedClient) Index() []ShardIndexEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entries := make([]ShardIndexEntry, 0, len(s.index))
+	for _, entry := range s.index {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// SaveIndex writes the index as JSON to w.
+func (s *ShardedClient) SaveIndex(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s.Index())
+}
+
+// LoadIndex reads the JSON index from r (see SaveIndex), adding
+// the entries to the index.
+func (s *ShardedClient) LoadIndex(r io.Reader) error {
+	var entries []ShardIndexEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return errors.Wrap(err, "decode index")
+	}
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, entry := range entries {
+		s.index[entry.ID] = entry
+	}
+	return nil
+}
+
+// setEntry adds or replaces the entry in the index.
+// Callers must hold changes.
+func (s *ShardedClient) setEntry(entry ShardIndexEntry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.index[entry.ID] = entry
+}
+
+// deleteEntry removes the entry from the index.
+// Callers must hold changes.
+func (s *ShardedClient) deleteEntry(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.index, id)
+}
+
+// AddShard adds a new shard and moves the faces of the people it now
+// owns onto it. Only faces in the index can be moved.
+//
+// The faces are taught to the new shard before it owns anyone; if any
+// of them can not be taught (for example because they have no
+// faceprint), the ones that were are removed again, and the shard is
+// not added. Once the shard is added, the moved faces are removed
+// from their old shards; any that could not be removed are reported
+// in the error, but the shard stays added.
+func (s *ShardedClient) AddShard(shard *Client) error {
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	for _, existing := range s.shards {
+		if existing == shard || existing.addr == shard.addr {
+			return errors.Errorf("shard %s already added", shard.addr)
+		}
+	}
+	ring := addToRing(s.ring, shard)
+	type move struct {
+		entry ShardIndexEntry
+		from  *Client
+	}
+	var moves []move
+	var failed []string
+	for _, entry := range s.index {
+		if ringShard(ring, entry.Name) != shard {
+			continue
+		}
+		if entry.Faceprint == "" {
+			failed = append(failed, entry.ID+": no faceprint")
+			continue
+		}
+		moves = append(moves, move{entry: entry, from: s.shard(entry.Name)})
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		return moves[i].entry.ID < moves[j].entry.ID
+	})
+	var taught []string
+	if len(failed) == 0 {
+		for _, m := range moves {
+			if err := shard.TeachFaceprint(m.entry.Faceprint, m.entry.ID, m.entry.Name); err != nil {
+				failed = append(failed, m.entry.ID+": "+err.Error())
+				break
+			}
+			taught = append(taught, m.entry.ID)
+		}
+	}
+	if len(failed) > 0 {
+		for _, id := range taught {
+			shard.Remove(id)
+		}
+		sort.Strings(failed)
+		return errors.Errorf("can not move faces: %s", strings.Join(failed, "; "))
+	}
+	s.lock.Lock()
+	s.shards = append(s.shards, shard)
+	s.ring = ring
+	s.lock.Unlock()
+	for _, m := range moves {
+		if err := m.from.Remove(m.entry.ID); err != nil {
+			failed = append(failed, m.entry.ID+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("shard added, but can not remove moved faces from old shards: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// move teaches the face in entry to the shard named in entry, and
+// removes it from the from shard.
+// Callers must hold changes.
+func (s *ShardedClient) move(entry ShardIndexEntry, from, to *Client) error {
+	if entry.Faceprint == "" {
+		return errors.New("no faceprint")
+	}
+	if err := to.TeachFaceprint(entry.Faceprint, entry.ID, entry.Name); err != nil {
+		return err
+	}
+	if err := from.Remove(entry.ID); err != nil {
+		return err
+	}
+	s.setEntry(entry)
+	return nil
+}
+
+// Teach teaches the owning shard the face in the io.Reader.
+// See Client.Teach for more information.
+func (s *ShardedClient) Teach(image io.Reader, id, name string) error {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return errors.Wrap(err, "read image")
+	}
+	return s.teach(id, name, func(c *Client, options *CheckOptions) ([]Face, error) {
+		return c.CheckWithOptions(bytes.NewReader(data), options)
+	}, func(c *Client) error {
+		return c.Teach(bytes.NewReader(data), id, name)
+	})
+}
+
+// TeachURL teaches the owning shard the face in the image at the
+// specified URL.
+// See Client.Teach for more information.
+func (s *ShardedClient) TeachURL(imageURL *url.URL, id, name string) error {
+	return s.teach(id, name, func(c *Client, options *CheckOptions) ([]Face, error) {
+		return c.CheckURLWithOptions(imageURL, options)
+	}, func(c *Client) error {
+		return c.TeachURL(imageURL, id, name)
+	})
+}
+
+// TeachBase64 teaches the owning shard the face in the Base64
+// encoded image.
+// See Client.Teach for more information.
+func (s *ShardedClient) TeachBase64(data, id, name string) error {
+	return s.teach(id, name, func(c *Client, options *CheckOptions) ([]Face, error) {
+		return c.CheckBase64WithOptions(data, options)
+	}, func(c *Client) error {
+		return c.TeachBase64(data, id, name)
+	})
+}
+
+// TeachFaceprint teaches the owning shard the face represented by
+// the faceprint.
+// See Client.Teach for more information.
+func (s *ShardedClient) TeachFaceprint(faceprint, id, name string) error {
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	shard := s.Shard(name)
+	if shard == nil {
+		return errors.New("no shards")
+	}
+	if err := shard.TeachFaceprint(faceprint, id, name); err != nil {
+		return err
+	}
+	s.setEntry(ShardIndexEntry{ID: id, Name: name, Faceprint: faceprint})
+	return nil
+}
+
+// teach teaches the owning shard the image, recording its faceprint
+// in the index.
+func (s *ShardedClient) teach(id, name string, check func(*Client, *CheckOptions) ([]Face, error), teachImage func(*Client) error) error {
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	shard := s.Shard(name)
+	if shard == nil {
+		return errors.New("no shards")
+	}
+	faceprint, err := shard.teachFaceprintOf(id, name, func(options *CheckOptions) ([]Face, error) {
+		return check(shard, options)
+	}, func() error {
+		return teachImage(shard)
+	})
+	if err != nil {
+		return err
+	}
+	s.setEntry(ShardIndexEntry{ID: id, Name: name, Faceprint: faceprint})
+	return nil
+}
+
+// Remove removes the face from the owning shard.
+// If the face is not in the index, it is removed from every shard.
+func (s *ShardedClient) Remove(id string) error {
+	if id == "" {
+		return errors.New("id can not be empty")
+	}
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	if entry, ok := s.index[id]; ok {
+		if err := s.shard(entry.Name).Remove(id); err != nil {
+			return err
+		}
+		s.deleteEntry(id)
+		return nil
+	}
+	var lastErr error
+	var removed bool
+	for _, shard := range s.shards {
+		if err := shard.Remove(id); err != nil {
+			lastErr = err
+			continue
+		}
+		removed = true
+	}
+	if !removed && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// Rename changes the name of the face, moving it to the shard that
+// owns the new name if necessary. The face must be in the index.
+func (s *ShardedClient) Rename(id, name string) error {
+	if id == "" {
+		return errors.New("id can not be empty")
+	}
+	if name == "" {
+		return errors.New("name can not be empty")
+	}
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	entry, ok := s.index[id]
+	if !ok {
+		return errors.Errorf("unknown id %q", id)
+	}
+	from, to := s.shard(entry.Name), s.shard(name)
+	entry.Name = name
+	if from == to {
+		if err := from.Rename(id, name); err != nil {
+			return err
+		}
+		s.setEntry(entry)
+		return nil
+	}
+	return s.move(entry, from, to)
+}
+
+// RenameAll changes the name of all faces with oldName. Faces in the
+// index are moved to the shard that owns newName if necessary; any
+// other faces are renamed in place.
+func (s *ShardedClient) RenameAll(oldName, newName string) error {
+	if oldName == "" {
+		return errors.New("oldName can not be empty")
+	}
+	if newName == "" {
+		return errors.New("newName can not be empty")
+	}
+	s.changes.Lock()
+	defer s.changes.Unlock()
+	from, to := s.shard(oldName), s.shard(newName)
+	if from == nil {
+		return errors.New("no shards")
+	}
+	var renamed []ShardIndexEntry
+	for id, entry := range s.index {
+		if entry.Name != oldName {
+			continue
+		}
+		entry.Name = newName
+		if from == to {
+			// renamed in place by from.RenameAll
+			renamed = append(renamed, entry)
+			continue
+		}
+		if err := s.move(entry, from, to); err != nil {
+			return errors.Wrapf(err, "move %s", id)
+		}
+	}
+	if err := from.RenameAll(oldName, newName); err != nil {
+		return err
+	}
+	for _, entry := range renamed {
+		s.setEntry(entry)
+	}
+	return nil
+}
+
+// Shards gets all the shards.
+func (s *ShardedClient) Shards() []*Client {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	shards := make([]*Client, len(s.shards))
+	copy(shards, s.shards)
+	return shards
+}
+
+// fanOut calls fn for every shard concurrently.
+func fanOut(shards []*Client, fn func(i int, shard *Client) error) error {
+	if len(shards) == 0 {
+		return errors.New("no shards")
+	}
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard *Client) {
+			defer wg.Done()
+			errs[i] = fn(i, shard)
+		}(i, shard)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrapf(err, "shard %s", shards[i].addr)
+		}
+	}
+	return nil
+}
+
+// Check checks the image in the io.Reader for faces on every shard,
+// returning the most confident match for each face.
+func (s *ShardedClient) Check(image io.Reader) ([]Face, error) {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	shards := s.Shards()
+	results := make([][]Face, len(shards))
+	err = fanOut(shards, func(i int, shard *Client) error {
+		var err error
+		results[i], err = shard.Check(bytes.NewReader(data))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeFaces(results), nil
+}
+
+// Similar checks the image in the io.Reader for similar faces on every
+// shard, returning the results ordered by confidence.
+func (s *ShardedClient) Similar(image io.Reader) ([]Similar, error) {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	shards := s.Shards()
+	results := make([][]Similar, len(shards))
+	err = fanOut(shards, func(i int, shard *Client) error {
+		var err error
+		results[i], err = shard.Similar(bytes.NewReader(data))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSimilar(results, 0), nil
+}
+
+// Similars checks the image in the io.Reader for similar faces on every
+// shard, returning at most limit of the most confident similar faces
+// for each face.
+func (s *ShardedClient) Similars(image io.Reader, limit int) ([]SimilarFace, error) {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	if limit < 1 {
+		limit = 5
+	}
+	shards := s.Shards()
+	results := make([][]SimilarFace, len(shards))
+	err = fanOut(shards, func(i int, shard *Client) error {
+		var err error
+		results[i], err = shard.Similars(bytes.NewReader(data), limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var faces []SimilarFace
+	similars := make(map[Rect][][]Similar)
+	for _, result := range results {
+		for _, face := range result {
+			if _, ok := similars[face.Rect]; !ok {
+				faces = append(faces, SimilarFace{Rect: face.Rect})
+			}
+			similars[face.Rect] = append(similars[face.Rect], face.SimilarFaces)
+		}
+	}
+	for i := range faces {
+		faces[i].SimilarFaces = mergeSimilar(similars[faces[i].Rect], limit)
+	}
+	return faces, nil
+}
+
+// mergeFaces merges the faces found by each shard, keeping the most
+// confident match for each face.
+func mergeFaces(results [][]Face) []Face {
+	var faces []Face
+	best := make(map[Rect]int)
+	for _, result := range results {
+		for _, face := range result {
+			i, ok := best[face.Rect]
+			if !ok {
+				best[face.Rect] = len(faces)
+				faces = append(faces, face)
+				continue
+			}
+			if face.Matched && (!faces[i].Matched || face.Confidence > faces[i].Confidence) {
+				faces[i] = face
+			}
+		}
+	}
+	return faces
+}
+
+// mergeSimilar merges and orders the similar faces by confidence,
+// keeping at most limit. If limit is zero, all are kept.
+func mergeSimilar(results [][]Similar, limit int) []Similar {
+	var similars []Similar
+	for _, result := range results {
+		similars = append(similars, result...)
+	}
+	sort.SliceStable(similars, func(i, j int) bool {
+		return similars[i].Confidence > similars[j].Confidence
+	})
+	if limit > 0 && len(similars) > limit {
+		similars = similars[:limit]
+	}
+	return similars
+}
@@ -0,0 +1,366 @@
+package facebox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+// fakeFacebox is an in-memory facebox where the faceprint of an image
+// is its contents, and faces match when their faceprints are the same
+// up to the first dash.
+type fakeFacebox struct {
+	lock       sync.Mutex
+	faces      map[string]string // faceprint by id
+	names      map[string]string // name by id
+	failTeach  bool
+	failRename bool
+	// teaching, if set, is sent each teach request before it is
+	// handled, and the request waits for release
+	teaching chan struct{}
+	release  chan struct{}
+}
+
+func newFakeFacebox() (*fakeFacebox, *httptest.Server) {
+	fb := &fakeFacebox{
+		faces: make(map[string]string),
+		names: make(map[string]string),
+	}
+	return fb, httptest.NewServer(fb)
+}
+
+func (fb *fakeFacebox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if fb.teaching != nil && r.URL.Path == "/facebox/teach" && r.Method == "POST" {
+		fb.teaching <- struct{}{}
+		<-fb.release
+	}
+	fb.lock.Lock()
+	defer fb.lock.Unlock()
+	respond := func(v map[string]interface{}) {
+		v["success"] = true
+		json.NewEncoder(w).Encode(v)
+	}
+	switch {
+	case r.URL.Path == "/facebox/check":
+		faceprint := r.FormValue("base64")
+		if f, _, err := r.FormFile("file"); err == nil {
+			b, _ := ioutil.ReadAll(f)
+			faceprint = string(b)
+		}
+		face := fb.match(facebox.Face{Rect: facebox.Rect{Width: 100, Height: 100}, Faceprint: faceprint})
+		if r.FormValue("faceprint") != "true" {
+			face.Faceprint = ""
+		}
+		respond(map[string]interface{}{"faces": []facebox.Face{face}})
+	case r.URL.Path == "/facebox/faceprint/check":
+		var request struct {
+			Faceprints []string
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		var faces []facebox.Face
+		for _, faceprint := range request.Faceprints {
+			faces = append(faces, fb.match(facebox.Face{Faceprint: faceprint}))
+		}
+		respond(map[string]interface{}{"faceprints": faces})
+	case r.URL.Path == "/facebox/similar" && r.Method == "GET":
+		id := r.URL.Query().Get("id")
+		similar := fb.similar(fb.faces[id], id)
+		if len(similar) > 2 {
+			similar = similar[:2]
+		}
+		respond(map[string]interface{}{"similar": similar})
+	case r.URL.Path == "/facebox/similar":
+		f, _, _ := r.FormFile("file")
+		b, _ := ioutil.ReadAll(f)
+		respond(map[string]interface{}{"similar": fb.similar(string(b), "")})
+	case r.URL.Path == "/facebox/teach" && r.Method == "POST":
+		if fb.failTeach {
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "disk full"})
+			return
+		}
+		id := r.FormValue("id")
+		fb.faces[id] = r.FormValue("faceprint")
+		fb.names[id] = r.FormValue("name")
+		respond(map[string]interface{}{})
+	case strings.HasPrefix(r.URL.Path, "/facebox/teach/") && r.Method == "DELETE":
+		id := strings.TrimPrefix(r.URL.Path, "/facebox/teach/")
+		if _, ok := fb.faces[id]; !ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "not found"})
+			return
+		}
+		delete(fb.faces, id)
+		delete(fb.names, id)
+		respond(map[string]interface{}{})
+	case strings.HasPrefix(r.URL.Path, "/facebox/teach/") && r.Method == "PATCH":
+		fb.names[strings.TrimPrefix(r.URL.Path, "/facebox/teach/")] = r.FormValue("name")
+		respond(map[string]interface{}{})
+	case r.URL.Path == "/facebox/rename":
+		if fb.failRename {
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "read only"})
+			return
+		}
+		for id, name := range fb.names {
+			if name == r.FormValue("from") {
+				fb.names[id] = r.FormValue("to")
+			}
+		}
+		respond(map[string]interface{}{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func samePerson(faceprint1, faceprint2 string) bool {
+	return strings.SplitN(faceprint1, "-", 2)[0] == strings.SplitN(faceprint2, "-", 2)[0]
+}
+
+// match sets the match fields of the face based on its faceprint.
+// Callers must hold the lock.
+func (fb *fakeFacebox) match(face facebox.Face) facebox.Face {
+	for id, taught := range fb.faces {
+		if samePerson(taught, face.Faceprint) {
+			face.ID, face.Name, face.Matched, face.Confidence = id, fb.names[id], true, 0.9
+		}
+	}
+	return face
+}
+
+// similar gets the faces similar to the faceprint, most similar
+// first, excluding the face with the specified ID.
+// Callers must hold the lock.
+func (fb *fakeFacebox) similar(faceprint, excludeID string) []facebox.Similar {
+	var similar []facebox.Similar
+	for id, taught := range fb.faces {
+		if id == excludeID {
+			continue
+		}
+		confidence := 0.2
+		if samePerson(taught, faceprint) {
+			confidence = 0.9
+		}
+		similar = append(similar, facebox.Similar{ID: id, Name: fb.names[id], Confidence: confidence})
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Confidence == similar[j].Confidence {
+			return similar[i].ID < similar[j].ID
+		}
+		return similar[i].Confidence > similar[j].Confidence
+	})
+	return similar
+}
+
+// roundTripper serves requests with the fake box for their host.
+type roundTripper map[string]*fakeFacebox
+
+func (rt roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	fb, ok := rt[r.URL.Host]
+	if !ok {
+		return nil, errors.New("unknown host " + r.URL.Host)
+	}
+	w := httptest.NewRecorder()
+	fb.ServeHTTP(w, r)
+	return w.Result(), nil
+}
+
+// newFakeShards makes fake boxes with fixed addresses, so they are
+// always in the same place on the consistent hashing ring.
+func newFakeShards(n int) ([]*fakeFacebox, []*facebox.Client) {
+	rt := make(roundTripper)
+	var boxes []*fakeFacebox
+	var clients []*facebox.Client
+	for i := 0; i < n; i++ {
+		host := "shard" + strconv.Itoa(i) + ".local"
+		box := &fakeFacebox{
+			faces: make(map[string]string),
+			names: make(map[string]string),
+		}
+		rt[host] = box
+		client := facebox.New("http://" + host)
+		client.HTTPClient = &http.Client{Transport: rt, Timeout: time.Minute}
+		boxes = append(boxes, box)
+		clients = append(clients, client)
+	}
+	return boxes, clients
+}
+
+func (fb *fakeFacebox) count() int {
+	fb.lock.Lock()
+	defer fb.lock.Unlock()
+	return len(fb.faces)
+}
+
+func TestShardedClient(t *testing.T) {
+	is := is.New(t)
+	boxes, clients := newFakeShards(3)
+	sharded := facebox.NewSharded(clients[0], clients[1])
+	names := []string{"John Lennon", "Ringo Starr", "Paul McCartney", "George Harrison", "Yoko Ono", "Brian Epstein"}
+	for i := 0; i < 50; i++ {
+		names = append(names, "Person "+strconv.Itoa(i))
+	}
+	for _, name := range names {
+		is.NoErr(sharded.Teach(strings.NewReader("face of "+name), name+".jpg", name))
+	}
+	is.Equal(boxes[0].count()+boxes[1].count(), len(names))
+	is.True(boxes[0].count() > 0)
+	is.True(boxes[1].count() > 0)
+	for _, name := range names {
+		owner := sharded.Shard(name)
+		is.True(owner == clients[0] || owner == clients[1])
+	}
+
+	faces, err := sharded.Check(strings.NewReader("face of Ringo Starr"))
+	is.NoErr(err)
+	is.Equal(len(faces), 1)
+	is.Equal(faces[0].Matched, true)
+	is.Equal(faces[0].Name, "Ringo Starr")
+
+	similar, err := sharded.Similar(strings.NewReader("face of Yoko Ono"))
+	is.NoErr(err)
+	is.Equal(len(similar), len(names))
+	is.Equal(similar[0].Name, "Yoko Ono")
+
+	is.NoErr(sharded.AddShard(clients[2]))
+	is.Equal(sharded.AddShard(clients[2]).Error(), "shard http://shard2.local already added")
+	is.Equal(len(sharded.Shards()), 3)
+	is.Equal(boxes[0].count()+boxes[1].count()+boxes[2].count(), len(names))
+	is.True(boxes[2].count() > 0)
+	for _, name := range names {
+		faces, err := sharded.Shard(name).Check(strings.NewReader("face of " + name))
+		is.NoErr(err)
+		is.Equal(faces[0].Name, name)
+	}
+
+	is.NoErr(sharded.Rename("Yoko Ono.jpg", "Yoko"))
+	faces, err = sharded.Check(strings.NewReader("face of Yoko Ono"))
+	is.NoErr(err)
+	is.Equal(faces[0].Name, "Yoko")
+	is.Equal(len(faces), 1)
+
+	is.NoErr(sharded.Remove("Brian Epstein.jpg"))
+	is.Equal(boxes[0].count()+boxes[1].count()+boxes[2].count(), len(names)-1)
+	is.Equal(len(sharded.Index()), len(names)-1)
+
+	var buf bytes.Buffer
+	is.NoErr(sharded.SaveIndex(&buf))
+	reloaded := facebox.NewSharded(clients...)
+	is.NoErr(reloaded.LoadIndex(&buf))
+	is.Equal(reloaded.Index(), sharded.Index())
+}
+
+func TestShardedClientSpread(t *testing.T) {
+	is := is.New(t)
+	_, clients := newFakeShards(3)
+	sharded := facebox.NewSharded(clients...)
+	counts := make(map[*facebox.Client]int)
+	for i := 0; i < 10000; i++ {
+		counts[sharded.Shard("Person "+strconv.Itoa(i))]++
+	}
+	for _, client := range clients {
+		// within 20% of an even split
+		is.True(counts[client] > 2666)
+		is.True(counts[client] < 4000)
+	}
+}
+
+func TestShardedClientAddShardFailure(t *testing.T) {
+	is := is.New(t)
+	boxes, clients := newFakeShards(2)
+	sharded := facebox.NewSharded(clients[0])
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, "Person "+strconv.Itoa(i))
+	}
+	for _, name := range names {
+		is.NoErr(sharded.TeachFaceprint("face of "+name, name+".jpg", name))
+	}
+	boxes[1].failTeach = true
+	err := sharded.AddShard(clients[1])
+	is.True(err != nil)
+	is.True(strings.HasPrefix(err.Error(), "can not move faces: "))
+	is.True(strings.Contains(err.Error(), "facebox: disk full"))
+	is.Equal(len(sharded.Shards()), 1)
+	is.Equal(boxes[0].count(), len(names))
+	for _, name := range names {
+		is.Equal(sharded.Shard(name), clients[0])
+	}
+
+	// faces without faceprints can not be moved, so nothing is
+	boxes[1].failTeach = false
+	var moving string
+	probe := facebox.NewSharded(clients...)
+	for _, name := range names {
+		if probe.Shard(name) == clients[1] {
+			moving = name
+			break
+		}
+	}
+	is.True(moving != "")
+	is.NoErr(sharded.LoadIndex(strings.NewReader(`[{"id": "nofaceprint.jpg", "name": "` + moving + `"}]`)))
+	err = sharded.AddShard(clients[1])
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "nofaceprint.jpg: no faceprint"))
+	is.Equal(boxes[1].count(), 0)
+	is.Equal(len(sharded.Shards()), 1)
+
+	// teaching it again records its faceprint
+	is.NoErr(sharded.TeachFaceprint("face of "+moving+"-2", "nofaceprint.jpg", moving))
+	is.NoErr(sharded.AddShard(clients[1]))
+	is.Equal(len(sharded.Shards()), 2)
+	is.True(boxes[1].count() > 0)
+	is.Equal(boxes[0].count()+boxes[1].count(), len(names)+1)
+}
+
+func TestShardedClientCheckDuringTeach(t *testing.T) {
+	is := is.New(t)
+	boxes, clients := newFakeShards(2)
+	sharded := facebox.NewSharded(clients...)
+	for _, box := range boxes {
+		box.teaching = make(chan struct{})
+		box.release = make(chan struct{})
+	}
+	taught := make(chan error)
+	go func() {
+		taught <- sharded.TeachFaceprint("face of John Lennon", "john.jpg", "John Lennon")
+	}()
+	var box *fakeFacebox
+	select {
+	case <-boxes[0].teaching:
+		box = boxes[0]
+	case <-boxes[1].teaching:
+		box = boxes[1]
+	}
+	// the teach is waiting on the box, but other shards can be checked
+	faces, err := sharded.Check(strings.NewReader("face of Ringo Starr"))
+	is.NoErr(err)
+	is.Equal(len(faces), 1)
+	is.Equal(faces[0].Matched, false)
+	close(box.release)
+	is.NoErr(<-taught)
+	is.Equal(len(sharded.Index()), 1)
+}
+
+func TestShardedClientRenameAllFailure(t *testing.T) {
+	is := is.New(t)
+	boxes, clients := newFakeShards(1)
+	sharded := facebox.NewSharded(clients...)
+	is.NoErr(sharded.TeachFaceprint("face of John Lennon", "john.jpg", "John Lennon"))
+	boxes[0].failRename = true
+	err := sharded.RenameAll("John Lennon", "John")
+	is.Equal(err.Error(), "facebox: read only")
+	is.Equal(sharded.Index()[0].Name, "John Lennon")
+	boxes[0].failRename = false
+	is.NoErr(sharded.RenameAll("John Lennon", "John"))
+	is.Equal(sharded.Index()[0].Name, "John")
+}
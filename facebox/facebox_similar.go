@@ -151,6 +151,12 @@ type SimilarFace struct {
 // Similars checks the image in the io.Reader for similar faces.
 // Will look for a maximum of limit similar faces for each face.
 func (c *Client) Similars(image io.Reader, limit int) ([]SimilarFace, error) {
+	return c.SimilarsWithOptions(image, limit, nil)
+}
+
+// SimilarsWithOptions checks the image in the io.Reader for similar faces,
+// using the CheckOptions to control the results.
+func (c *Client) SimilarsWithOptions(image io.Reader, limit int, options *CheckOptions) ([]SimilarFace, error) {
 	var buf bytes.Buffer
 	w := multipart.NewWriter(&buf)
 	fw, err := w.CreateFormFile("file", "image.dat")
@@ -190,12 +196,18 @@ func (c *Client) Similars(image io.Reader, limit int) ([]SimilarFace, error) {
 	if err != nil {
 		return nil, err
 	}
-	return similarsResponse.Faces, nil
+	return options.filterSimilars(similarsResponse.Faces), nil
 }
 
 // SimilarsURL checks the image at the specified URL for similar faces.
 // Will look for a maximum of limit similar faces for each face.
 func (c *Client) SimilarsURL(imageURL *url.URL, limit int) ([]SimilarFace, error) {
+	return c.SimilarsURLWithOptions(imageURL, limit, nil)
+}
+
+// SimilarsURLWithOptions checks the image at the specified URL for similar faces,
+// using the CheckOptions to control the results.
+func (c *Client) SimilarsURLWithOptions(imageURL *url.URL, limit int, options *CheckOptions) ([]SimilarFace, error) {
 	u, err := url.Parse(c.addr + "/facebox/similars")
 	if err != nil {
 		return nil, err
@@ -225,12 +237,18 @@ func (c *Client) SimilarsURL(imageURL *url.URL, limit int) ([]SimilarFace, error
 	if err != nil {
 		return nil, err
 	}
-	return similarsResponse.Faces, nil
+	return options.filterSimilars(similarsResponse.Faces), nil
 }
 
 // SimilarsBase64 checks the Base64 encoded image for similar faces.
 // Will look for a maximum of limit similar faces for each face.
 func (c *Client) SimilarsBase64(data string, limit int) ([]SimilarFace, error) {
+	return c.SimilarsBase64WithOptions(data, limit, nil)
+}
+
+// SimilarsBase64WithOptions checks the Base64 encoded image for similar faces,
+// using the CheckOptions to control the results.
+func (c *Client) SimilarsBase64WithOptions(data string, limit int, options *CheckOptions) ([]SimilarFace, error) {
 	u, err := url.Parse(c.addr + "/facebox/similars")
 	if err != nil {
 		return nil, err
@@ -257,5 +275,5 @@ func (c *Client) SimilarsBase64(data string, limit int) ([]SimilarFace, error) {
 	if err != nil {
 		return nil, err
 	}
-	return similarsResponse.Faces, nil
+	return options.filterSimilars(similarsResponse.Faces), nil
 }
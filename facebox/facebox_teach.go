@@ -170,3 +170,24 @@ func (c *Client) Remove(id string) error {
 	}
 	return nil
 }
+
+// teachFaceprintOf gets the faceprint of an image using check, and
+// teaches the faceprint so that it can be recorded and taught again
+// later. If the image does not contain exactly one face, teachImage
+// is used instead so facebox decides what to do, and the returned
+// faceprint is empty.
+func (c *Client) teachFaceprintOf(id, name string, check func(*CheckOptions) ([]Face, error), teachImage func() error) (string, error) {
+	options := NewCheckOptions()
+	options.Faceprint()
+	faces, err := check(options)
+	if err != nil {
+		return "", err
+	}
+	if len(faces) != 1 || faces[0].Faceprint == "" {
+		return "", teachImage()
+	}
+	if err := c.TeachFaceprint(faces[0].Faceprint, id, name); err != nil {
+		return "", err
+	}
+	return faces[0].Faceprint, nil
+}
@@ -0,0 +1,133 @@
+package facebox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// TeachValidationOptions control which images are rejected by
+// the TeachValidated methods.
+type TeachValidationOptions struct {
+	// MinFaceSize is the minimum width and height (in pixels) of
+	// the face. Zero means any size is allowed.
+	MinFaceSize int
+	// OtherNameConfidence is the confidence at or above which a face
+	// that already matches a different name is rejected.
+	// Zero disables this check.
+	OtherNameConfidence float64
+}
+
+// RejectReason describes why an image was rejected for teaching.
+type RejectReason string
+
+const (
+	// RejectNoFaces indicates that no face was found in the image.
+	RejectNoFaces RejectReason = "no faces"
+	// RejectMultipleFaces indicates that more than one face was found
+	// in the image.
+	RejectMultipleFaces RejectReason = "multiple faces"
+	// RejectFaceTooSmall indicates that the face was smaller than
+	// TeachValidationOptions.MinFaceSize.
+	RejectFaceTooSmall RejectReason = "face too small"
+	// RejectMatchesOtherName indicates that the face already matches
+	// a different person.
+	RejectMatchesOtherName RejectReason = "matches other name"
+)
+
+// ErrTeachRejected is returned by the TeachValidated methods when an
+// image is not suitable for teaching.
+type ErrTeachRejected struct {
+	// Reason is why the image was rejected.
+	Reason RejectReason
+	// Faces is the number of faces found in the image.
+	Faces int
+	// Face is the face that caused the rejection, if any.
+	Face *Face
+}
+
+func (e *ErrTeachRejected) Error() string {
+	switch e.Reason {
+	case RejectMultipleFaces:
+		return fmt.Sprintf("facebox: teach rejected: %s (%d)", e.Reason, e.Faces)
+	case RejectFaceTooSmall:
+		return fmt.Sprintf("facebox: teach rejected: %s (%dx%d)", e.Reason, e.Face.Rect.Width, e.Face.Rect.Height)
+	case RejectMatchesOtherName:
+		return fmt.Sprintf("facebox: teach rejected: %s (%s %.2f)", e.Reason, e.Face.Name, e.Face.Confidence)
+	}
+	return "facebox: teach rejected: " + string(e.Reason)
+}
+
+// TeachValidated checks the image in the io.Reader before teaching it,
+// and returns an *ErrTeachRejected error if the image does not contain
+// exactly one suitable face.
+// See Teach for more information.
+func (c *Client) TeachValidated(image io.Reader, id, name string, options *TeachValidationOptions) error {
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return errors.Wrap(err, "read image")
+	}
+	faces, err := c.Check(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err := validateTeachFaces(faces, name, options); err != nil {
+		return err
+	}
+	return c.Teach(bytes.NewReader(data), id, name)
+}
+
+// TeachValidatedURL checks the image at the specified URL before
+// teaching it.
+// See TeachValidated for more information.
+func (c *Client) TeachValidatedURL(imageURL *url.URL, id, name string, options *TeachValidationOptions) error {
+	faces, err := c.CheckURL(imageURL)
+	if err != nil {
+		return err
+	}
+	if err := validateTeachFaces(faces, name, options); err != nil {
+		return err
+	}
+	return c.TeachURL(imageURL, id, name)
+}
+
+// TeachValidatedBase64 checks the Base64 encoded image before
+// teaching it.
+// See TeachValidated for more information.
+func (c *Client) TeachValidatedBase64(data, id, name string, options *TeachValidationOptions) error {
+	faces, err := c.CheckBase64(data)
+	if err != nil {
+		return err
+	}
+	if err := validateTeachFaces(faces, name, options); err != nil {
+		return err
+	}
+	return c.TeachBase64(data, id, name)
+}
+
+// validateTeachFaces returns an *ErrTeachRejected if the faces
+// are not suitable for teaching the named person.
+func validateTeachFaces(faces []Face, name string, options *TeachValidationOptions) error {
+	if options == nil {
+		options = &TeachValidationOptions{}
+	}
+	switch len(faces) {
+	case 0:
+		return &ErrTeachRejected{Reason: RejectNoFaces}
+	case 1:
+	default:
+		return &ErrTeachRejected{Reason: RejectMultipleFaces, Faces: len(faces)}
+	}
+	face := faces[0]
+	if face.Rect.Width < options.MinFaceSize || face.Rect.Height < options.MinFaceSize {
+		return &ErrTeachRejected{Reason: RejectFaceTooSmall, Faces: 1, Face: &face}
+	}
+	if options.OtherNameConfidence > 0 && face.Matched && face.Name != name && face.Confidence >= options.OtherNameConfidence {
+		return &ErrTeachRejected{Reason: RejectMatchesOtherName, Faces: 1, Face: &face}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package facebox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestTeachValidated(t *testing.T) {
+	is := is.New(t)
+	var checkResponse string
+	var taught bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/facebox/check":
+			io.WriteString(w, checkResponse)
+		case "/facebox/teach":
+			is.Equal(r.FormValue("id"), "john1.jpg")
+			is.Equal(r.FormValue("name"), "John Lennon")
+			taught = true
+			io.WriteString(w, `{"success": true}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	options := &facebox.TeachValidationOptions{
+		MinFaceSize:         100,
+		OtherNameConfidence: 0.8,
+	}
+
+	for _, test := range []struct {
+		response string
+		reason   facebox.RejectReason
+		err      string
+	}{
+		{
+			response: `{"success": true, "faces": []}`,
+			reason:   facebox.RejectNoFaces,
+			err:      "facebox: teach rejected: no faces",
+		},
+		{
+			response: `{"success": true, "faces": [
+				{"rect": {"top": 0, "left": 0, "width": 120, "height": 120}},
+				{"rect": {"top": 0, "left": 200, "width": 120, "height": 120}}
+			]}`,
+			reason: facebox.RejectMultipleFaces,
+			err:    "facebox: teach rejected: multiple faces (2)",
+		},
+		{
+			response: `{"success": true, "faces": [
+				{"rect": {"top": 0, "left": 0, "width": 120, "height": 50}}
+			]}`,
+			reason: facebox.RejectFaceTooSmall,
+			err:    "facebox: teach rejected: face too small (120x50)",
+		},
+		{
+			response: `{"success": true, "faces": [
+				{"rect": {"top": 0, "left": 0, "width": 120, "height": 120},
+				"matched": true, "name": "Ringo Starr", "confidence": 0.9}
+			]}`,
+			reason: facebox.RejectMatchesOtherName,
+			err:    "facebox: teach rejected: matches other name (Ringo Starr 0.90)",
+		},
+	} {
+		checkResponse = test.response
+		taught = false
+		err := fb.TeachValidated(strings.NewReader(`(pretend this is image data)`), "john1.jpg", "John Lennon", options)
+		is.True(err != nil)
+		rejected, ok := err.(*facebox.ErrTeachRejected)
+		is.True(ok)
+		is.Equal(rejected.Reason, test.reason)
+		is.Equal(err.Error(), test.err)
+		is.Equal(taught, false)
+	}
+
+	checkResponse = `{"success": true, "faces": [
+		{"rect": {"top": 0, "left": 0, "width": 120, "height": 120},
+		"matched": true, "name": "Ringo Starr", "confidence": 0.5}
+	]}`
+	err := fb.TeachValidated(strings.NewReader(`(pretend this is image data)`), "john1.jpg", "John Lennon", options)
+	is.NoErr(err)
+	is.Equal(taught, true)
+}
+
+func TestTeachValidatedURL(t *testing.T) {
+	is := is.New(t)
+	imageURL, err := url.Parse("https://test.machinebox.io/image1.png")
+	is.NoErr(err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.FormValue("url"), imageURL.String())
+		switch r.URL.Path {
+		case "/facebox/check":
+			io.WriteString(w, `{"success": true, "faces": [
+				{"rect": {"top": 0, "left": 0, "width": 120, "height": 120}}
+			]}`)
+		case "/facebox/teach":
+			io.WriteString(w, `{"success": true}`)
+		}
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	err = fb.TeachValidatedURL(imageURL, "john1.jpg", "John Lennon", nil)
+	is.NoErr(err)
+}
@@ -0,0 +1,224 @@
+package facebox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// TeachDirOptions control the behaviour of TeachDir.
+type TeachDirOptions struct {
+	// Concurrency is the maximum number of images that will be
+	// taught at the same time. Defaults to 4.
+	Concurrency int
+	// ID generates the ID for an image from its path (relative to
+	// the root directory, using forward slashes) and its contents.
+	// Defaults to IDFromPath.
+	ID func(relpath string, data []byte) string
+	// Previous is the report from an earlier run. Images whose IDs
+	// were taught successfully in that run will be skipped.
+	Previous *TeachDirReport
+	// Validation, if set, causes each image to be checked with
+	// TeachValidated before it is taught.
+	Validation *TeachValidationOptions
+}
+
+// TeachDirReport describes the outcome of a TeachDir operation.
+type TeachDirReport struct {
+	// Taught are the images that were successfully taught.
+	Taught []TeachDirResult `json:"taught"`
+	// Skipped are the images that were not taught because
+	// they were taught in a previous run.
+	Skipped []TeachDirResult `json:"skipped"`
+	// Failed are the images that could not be taught.
+	Failed []TeachDirResult `json:"failed"`
+}
+
+// TeachDirResult describes the outcome of teaching a single image.
+type TeachDirResult struct {
+	// Path is the path of the image relative to the root directory.
+	Path string `json:"path"`
+	// ID is the ID the image was taught with.
+	ID string `json:"id"`
+	// Name is the name of the person.
+	Name string `json:"name"`
+	// Reason describes why the image failed or was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// TaughtIDs gets the set of IDs that have been taught, including
+// those that were taught in previous runs.
+func (r *TeachDirReport) TaughtIDs() map[string]bool {
+	ids := make(map[string]bool)
+	if r == nil {
+		return ids
+	}
+	for _, result := range r.Taught {
+		ids[result.ID] = true
+	}
+	for _, result := range r.Skipped {
+		ids[result.ID] = true
+	}
+	return ids
+}
+
+// IDFromPath makes an ID from the relative path of the image.
+// Slashes are replaced with underscores since IDs are used in
+// URL paths. Underscores and tildes already in the path are escaped
+// as "~_" and "~~" so different paths always make different IDs.
+func IDFromPath(relpath string, data []byte) string {
+	return idPathReplacer.Replace(relpath)
+}
+
+var idPathReplacer = strings.NewReplacer("~", "~~", "_", "~_", "/", "_")
+
+// IDFromContentHash makes an ID from the SHA-1 hash of the image
+// data, so the ID remains stable if the file is moved or renamed.
+func IDFromContentHash(relpath string, data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// imageExts are the file extensions considered to be images
+// by TeachDir.
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".bmp":  true,
+	".webp": true,
+}
+
+// TeachDir teaches facebox all the images in the directory tree.
+// Each subdirectory of dir should be named after the person whose
+// face appears in the images it contains, for example:
+//
+//	dir/John Lennon/1.jpg
+//	dir/John Lennon/2.jpg
+//	dir/Ringo Starr/photo.png
+//
+// Images in nested directories are taught with the name of the top
+// level subdirectory. Images directly inside dir are reported as
+// failed.
+// TeachDir returns the report along with ctx.Err() if the context
+// is cancelled before all images are taught.
+func (c *Client) TeachDir(ctx context.Context, dir string, options *TeachDirOptions) (*TeachDirReport, error) {
+	if options == nil {
+		options = &TeachDirOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	idFunc := options.ID
+	if idFunc == nil {
+		idFunc = IDFromPath
+	}
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != dir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !imageExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk directory")
+	}
+	previous := options.Previous.TaughtIDs()
+	report := &TeachDirReport{}
+	var lock sync.Mutex
+	record := func(list *[]TeachDirResult, result TeachDirResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		*list = append(*list, result)
+	}
+	teachImage := c.Teach
+	if options.Validation != nil {
+		teachImage = func(image io.Reader, id, name string) error {
+			return c.TeachValidated(image, id, name, options.Validation)
+		}
+	}
+	teach := func(path string) {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			record(&report.Failed, TeachDirResult{Path: path, Reason: err.Error()})
+			return
+		}
+		rel = filepath.ToSlash(rel)
+		result := TeachDirResult{Path: rel}
+		segs := strings.Split(rel, "/")
+		if len(segs) < 2 {
+			result.Reason = "image is not inside a person directory"
+			record(&report.Failed, result)
+			return
+		}
+		result.Name = segs[0]
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			result.Reason = err.Error()
+			record(&report.Failed, result)
+			return
+		}
+		result.ID = idFunc(rel, data)
+		if previous[result.ID] {
+			result.Reason = "already taught"
+			record(&report.Skipped, result)
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			result.Reason = err.Error()
+			record(&report.Failed, result)
+			return
+		}
+		if err := teachImage(bytes.NewReader(data), result.ID, result.Name); err != nil {
+			result.Reason = err.Error()
+			record(&report.Failed, result)
+			return
+		}
+		record(&report.Taught, result)
+	}
+	pathsChan := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range pathsChan {
+				teach(path)
+			}
+		}()
+	}
+	for _, path := range paths {
+		pathsChan <- path
+	}
+	close(pathsChan)
+	wg.Wait()
+	for _, list := range [][]TeachDirResult{report.Taught, report.Skipped, report.Failed} {
+		list := list
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	}
+	return report, ctx.Err()
+}
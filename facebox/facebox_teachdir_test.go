@@ -0,0 +1,116 @@
+package facebox_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestTeachDir(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "facebox-teachdir")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"John Lennon/1.jpg":        "john1",
+		"John Lennon/2.jpg":        "john2",
+		"John Lennon/notes.txt":    "not an image",
+		"Ringo Starr/live/1.png":   "ringo1",
+		"Ringo Starr/.hidden.jpg":  "hidden",
+		"loose.jpg":                "loose",
+		"Paul McCartney/bad.jpeg":  "bad",
+		".thumbnails/whatever.jpg": "thumb",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		is.NoErr(os.MkdirAll(filepath.Dir(path), 0777))
+		is.NoErr(ioutil.WriteFile(path, []byte(content), 0666))
+	}
+	var lock sync.Mutex
+	taught := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/teach")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		if string(b) == "bad" {
+			io.WriteString(w, `{"success": false, "error": "no faces found"}`)
+			return
+		}
+		lock.Lock()
+		taught[r.FormValue("id")] = r.FormValue("name")
+		lock.Unlock()
+		io.WriteString(w, `{"success": true}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+
+	report, err := fb.TeachDir(context.Background(), dir, &facebox.TeachDirOptions{Concurrency: 2})
+	is.NoErr(err)
+	is.Equal(len(report.Taught), 3)
+	is.Equal(report.Taught[0].Path, "John Lennon/1.jpg")
+	is.Equal(report.Taught[0].ID, "John Lennon_1.jpg")
+	is.Equal(report.Taught[0].Name, "John Lennon")
+	is.Equal(report.Taught[2].Path, "Ringo Starr/live/1.png")
+	is.Equal(report.Taught[2].Name, "Ringo Starr")
+	is.Equal(len(report.Skipped), 0)
+	is.Equal(len(report.Failed), 2)
+	is.Equal(report.Failed[0].Path, "Paul McCartney/bad.jpeg")
+	is.Equal(report.Failed[0].Reason, "facebox: no faces found")
+	is.Equal(report.Failed[1].Path, "loose.jpg")
+	is.Equal(report.Failed[1].Reason, "image is not inside a person directory")
+	is.Equal(taught["John Lennon_2.jpg"], "John Lennon")
+	is.Equal(taught["Ringo Starr_live_1.png"], "Ringo Starr")
+
+	taught = make(map[string]string)
+	report, err = fb.TeachDir(context.Background(), dir, &facebox.TeachDirOptions{
+		Previous: report,
+	})
+	is.NoErr(err)
+	is.Equal(len(report.Taught), 0)
+	is.Equal(len(report.Skipped), 3)
+	is.Equal(report.Skipped[0].Reason, "already taught")
+	is.Equal(len(report.Failed), 2)
+	is.Equal(len(taught), 0)
+}
+
+func TestTeachDirContentHash(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "facebox-teachdir")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	is.NoErr(os.MkdirAll(filepath.Join(dir, "John Lennon"), 0777))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "John Lennon", "1.jpg"), []byte("john1"), 0666))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.FormValue("id"), "cf03e66c4d3d16031d814431b06536adee9cb685")
+		io.WriteString(w, `{"success": true}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+	report, err := fb.TeachDir(context.Background(), dir, &facebox.TeachDirOptions{
+		ID: facebox.IDFromContentHash,
+	})
+	is.NoErr(err)
+	is.Equal(len(report.Taught), 1)
+	is.Equal(report.Taught[0].ID, facebox.IDFromContentHash("", []byte("john1")))
+}
+
+func TestIDFromPath(t *testing.T) {
+	is := is.New(t)
+	is.Equal(facebox.IDFromPath("John Lennon/1.jpg", nil), "John Lennon_1.jpg")
+	is.Equal(facebox.IDFromPath("a_b/c.jpg", nil), "a~_b_c.jpg")
+	is.Equal(facebox.IDFromPath("a/b_c.jpg", nil), "a_b~_c.jpg")
+	is.Equal(facebox.IDFromPath("a~/b.jpg", nil), "a~~_b.jpg")
+	is.True(facebox.IDFromPath("a_/b.jpg", nil) != facebox.IDFromPath("a/_b.jpg", nil))
+}
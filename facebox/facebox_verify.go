@@ -0,0 +1,144 @@
+package facebox
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// VerifySource is an image, or a stored faceprint, that can be
+// passed to Verify.
+type VerifySource struct {
+	image     io.Reader
+	url       *url.URL
+	base64    string
+	faceprint string
+}
+
+// VerifyImage makes a VerifySource from the image in the io.Reader.
+func VerifyImage(image io.Reader) VerifySource {
+	return VerifySource{image: image}
+}
+
+// VerifyURL makes a VerifySource from the image at the specified URL.
+func VerifyURL(imageURL *url.URL) VerifySource {
+	return VerifySource{url: imageURL}
+}
+
+// VerifyBase64 makes a VerifySource from the Base64 encoded image.
+func VerifyBase64(data string) VerifySource {
+	return VerifySource{base64: data}
+}
+
+// VerifyFaceprint makes a VerifySource from a faceprint, usually one
+// that was stored from a previous check.
+func VerifyFaceprint(faceprint string) VerifySource {
+	return VerifySource{faceprint: faceprint}
+}
+
+// VerifyOptions control the behaviour of Verify.
+type VerifyOptions struct {
+	// Threshold is the confidence at or above which the faces are
+	// considered to belong to the same person. Defaults to 0.6.
+	Threshold float64
+}
+
+// Verification is the result of comparing two faces.
+type Verification struct {
+	// Match is whether the faces belong to the same person.
+	Match bool `json:"match"`
+	// Confidence is the confidence that the faces belong to
+	// the same person.
+	Confidence float64 `json:"confidence"`
+	// Threshold is the threshold the Confidence was compared with.
+	Threshold float64 `json:"threshold"`
+}
+
+// ErrVerifyFaceCount is returned by Verify when an image does not
+// contain exactly one face.
+type ErrVerifyFaceCount struct {
+	// Source is which source the error relates to; 1 for the
+	// first and 2 for the second.
+	Source int
+	// Faces is the number of faces that were found.
+	Faces int
+}
+
+func (e *ErrVerifyFaceCount) Error() string {
+	which := "first"
+	if e.Source == 2 {
+		which = "second"
+	}
+	if e.Faces == 0 {
+		return fmt.Sprintf("facebox: verify: %s image: no faces", which)
+	}
+	return fmt.Sprintf("facebox: verify: %s image: multiple faces (%d)", which, e.Faces)
+}
+
+// Verify determines whether the faces in two sources belong to the
+// same person (1:1 verification), unlike Check which identifies faces
+// against everybody facebox has been taught (1:N identification).
+// Each image must contain exactly one face, otherwise an
+// *ErrVerifyFaceCount error is returned.
+func (c *Client) Verify(source1, source2 VerifySource, options *VerifyOptions) (*Verification, error) {
+	if options == nil {
+		options = &VerifyOptions{}
+	}
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = 0.6
+	}
+	faceprint1, err := c.verifyFaceprint(source1, 1)
+	if err != nil {
+		return nil, err
+	}
+	faceprint2, err := c.verifyFaceprint(source2, 2)
+	if err != nil {
+		return nil, err
+	}
+	confidences, err := c.CompareFaceprints(faceprint1, []string{faceprint2})
+	if err != nil {
+		return nil, err
+	}
+	if len(confidences) != 1 {
+		return nil, errors.Errorf("facebox: verify: expected 1 confidence but got %d", len(confidences))
+	}
+	return &Verification{
+		Match:      confidences[0] >= threshold,
+		Confidence: confidences[0],
+		Threshold:  threshold,
+	}, nil
+}
+
+// verifyFaceprint gets the faceprint of the only face in the source.
+func (c *Client) verifyFaceprint(source VerifySource, n int) (string, error) {
+	if source.faceprint != "" {
+		return source.faceprint, nil
+	}
+	options := NewCheckOptions()
+	options.Faceprint()
+	var faces []Face
+	var err error
+	switch {
+	case source.image != nil:
+		faces, err = c.CheckWithOptions(source.image, options)
+	case source.url != nil:
+		faces, err = c.CheckURLWithOptions(source.url, options)
+	case source.base64 != "":
+		faces, err = c.CheckBase64WithOptions(source.base64, options)
+	default:
+		return "", errors.New("facebox: verify: empty source")
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(faces) != 1 {
+		return "", &ErrVerifyFaceCount{Source: n, Faces: len(faces)}
+	}
+	if faces[0].Faceprint == "" {
+		return "", errors.New("facebox: verify: no faceprint returned")
+	}
+	return faces[0].Faceprint, nil
+}
@@ -0,0 +1,110 @@
+package facebox_test
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/matryer/is"
+)
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+	imageURL, err := url.Parse("https://test.machinebox.io/selfie.png")
+	is.NoErr(err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/facebox/check":
+			is.Equal(r.FormValue("faceprint"), "true")
+			if r.FormValue("url") != "" {
+				is.Equal(r.FormValue("url"), imageURL.String())
+				io.WriteString(w, `{"success": true, "faces": [{"faceprint": "selfie-faceprint"}]}`)
+				return
+			}
+			f, _, err := r.FormFile("file")
+			is.NoErr(err)
+			defer f.Close()
+			b, err := ioutil.ReadAll(f)
+			is.NoErr(err)
+			is.Equal(string(b), "(pretend this is image data)")
+			io.WriteString(w, `{"success": true, "faces": [{"faceprint": "idcard-faceprint"}]}`)
+		case "/facebox/faceprint/compare":
+			var request struct {
+				Target     string   `json:"target"`
+				Faceprints []string `json:"faceprints"`
+			}
+			is.NoErr(json.NewDecoder(r.Body).Decode(&request))
+			is.Equal(request.Target, "selfie-faceprint")
+			is.Equal(len(request.Faceprints), 1)
+			confidence := 0.3
+			if request.Faceprints[0] == "idcard-faceprint" {
+				confidence = 0.8
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":     true,
+				"confidences": []float64{confidence},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+
+	verification, err := fb.Verify(
+		facebox.VerifyURL(imageURL),
+		facebox.VerifyImage(strings.NewReader("(pretend this is image data)")),
+		nil,
+	)
+	is.NoErr(err)
+	is.Equal(verification.Match, true)
+	is.Equal(verification.Confidence, 0.8)
+	is.Equal(verification.Threshold, 0.6)
+
+	verification, err = fb.Verify(
+		facebox.VerifyURL(imageURL),
+		facebox.VerifyFaceprint("someone-else-faceprint"),
+		&facebox.VerifyOptions{Threshold: 0.2},
+	)
+	is.NoErr(err)
+	is.Equal(verification.Match, true)
+	is.Equal(verification.Confidence, 0.3)
+
+	verification, err = fb.Verify(
+		facebox.VerifyFaceprint("selfie-faceprint"),
+		facebox.VerifyFaceprint("someone-else-faceprint"),
+		nil,
+	)
+	is.NoErr(err)
+	is.Equal(verification.Match, false)
+}
+
+func TestVerifyFaceCount(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		if r.FormValue("base64") == "group" {
+			io.WriteString(w, `{"success": true, "faces": [{"faceprint": "1"}, {"faceprint": "2"}]}`)
+			return
+		}
+		io.WriteString(w, `{"success": true, "faces": []}`)
+	}))
+	defer srv.Close()
+	fb := facebox.New(srv.URL)
+
+	_, err := fb.Verify(facebox.VerifyBase64("empty"), facebox.VerifyFaceprint("x"), nil)
+	is.Equal(err.Error(), "facebox: verify: first image: no faces")
+	countErr, ok := err.(*facebox.ErrVerifyFaceCount)
+	is.True(ok)
+	is.Equal(countErr.Source, 1)
+	is.Equal(countErr.Faces, 0)
+
+	_, err = fb.Verify(facebox.VerifyFaceprint("x"), facebox.VerifyBase64("group"), nil)
+	is.Equal(err.Error(), "facebox: verify: second image: multiple faces (2)")
+}
@@ -0,0 +1,165 @@
+// Package gifutil checks the frames of animated GIFs.
+package gifutil
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Options control which frames are checked.
+type Options struct {
+	// Every checks every Nth frame. Defaults to 1.
+	Every int
+	// Interval is the minimum time between checked frames.
+	// If set, Every is ignored.
+	Interval time.Duration
+	// MaxFrames is the maximum number of frames that will be
+	// checked. Defaults to 50.
+	MaxFrames int
+	// Concurrency is the maximum number of frames that will be
+	// checked at the same time. Defaults to 4.
+	Concurrency int
+}
+
+// Frame is a single frame of an animated GIF.
+type Frame struct {
+	// Index is the index of the frame in the GIF, starting at 0.
+	Index int
+	// Offset is when the frame is shown.
+	Offset time.Duration
+	// Data is the frame encoded as a JPEG image.
+	Data []byte
+}
+
+// CheckFunc checks a frame. If stop is true, no more frames are checked.
+type CheckFunc func(frame Frame) (stop bool, err error)
+
+// Check decodes the GIF and calls check for the sampled frames,
+// concurrently. Frames are composited, so each one is the complete
+// image that is shown. It returns the total number of frames in the
+// GIF, and whether checking stopped early.
+func Check(ctx context.Context, r io.Reader, o Options, check CheckFunc) (total int, stopped bool, err error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "decode gif")
+	}
+	if o.Every < 1 {
+		o.Every = 1
+	}
+	if o.MaxFrames < 1 {
+		o.MaxFrames = 50
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 4
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+	)
+	frames := make(chan Frame)
+	for i := 0; i < o.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for frame := range frames {
+				if ctx.Err() != nil {
+					continue
+				}
+				stop, err := check(frame)
+				if err != nil {
+					err = errors.Wrapf(err, "frame %d", frame.Index)
+				}
+				lock.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if stop {
+					stopped = true
+				}
+				lock.Unlock()
+				if stop || err != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+	produceErr := produce(ctx, g, o, frames)
+	close(frames)
+	wg.Wait()
+	if firstErr != nil {
+		return len(g.Image), stopped, firstErr
+	}
+	if produceErr != nil && !stopped {
+		return len(g.Image), false, produceErr
+	}
+	return len(g.Image), stopped, nil
+}
+
+// produce composites the frames of the GIF, and sends the sampled
+// ones on frames until they have all been sent or ctx is done.
+func produce(ctx context.Context, g *gif.GIF, o Options, frames chan<- Frame) error {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+	var (
+		offset  time.Duration
+		last    time.Duration
+		sampled int
+	)
+	for i, img := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+		sample := i%o.Every == 0
+		if o.Interval > 0 {
+			sample = sampled == 0 || offset-last >= o.Interval
+		}
+		if sample {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: 90}); err != nil {
+				return errors.Wrapf(err, "encode frame %d", i)
+			}
+			select {
+			case frames <- Frame{Index: i, Offset: offset, Data: buf.Bytes()}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			sampled++
+			last = offset
+			if sampled == o.MaxFrames {
+				return nil
+			}
+		}
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+		if i < len(g.Delay) {
+			offset += time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+	}
+	return nil
+}
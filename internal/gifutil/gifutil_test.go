@@ -0,0 +1,138 @@
+package gifutil_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/internal/gifutil"
+	"github.com/matryer/is"
+)
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+func frame(bounds image.Rectangle, c color.Color) *image.Paletted {
+	img := image.NewPaletted(bounds, palette.Plan9)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encode(is *is.I, g *gif.GIF) *bytes.Buffer {
+	var buf bytes.Buffer
+	is.NoErr(gif.EncodeAll(&buf, g))
+	return &buf
+}
+
+func colorAt(is *is.I, data []byte, x, y int) (r, g, b uint32) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	is.NoErr(err)
+	r, g, b, _ = img.At(x, y).RGBA()
+	return r >> 8, g >> 8, b >> 8
+}
+
+func TestCheckComposites(t *testing.T) {
+	is := is.New(t)
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 32, 32), red),
+			frame(image.Rect(0, 0, 8, 8), blue),
+			frame(image.Rect(24, 24, 32, 32), blue),
+		},
+		Delay:    []int{10, 20, 30},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalPrevious, gif.DisposalNone},
+	}
+	var lock sync.Mutex
+	frames := make(map[int]gifutil.Frame)
+	total, stopped, err := gifutil.Check(context.Background(), encode(is, g), gifutil.Options{}, func(f gifutil.Frame) (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		frames[f.Index] = f
+		return false, nil
+	})
+	is.NoErr(err)
+	is.Equal(total, 3)
+	is.Equal(stopped, false)
+	is.Equal(len(frames), 3)
+	is.Equal(frames[1].Offset, 100*time.Millisecond)
+	is.Equal(frames[2].Offset, 300*time.Millisecond)
+	// the second frame is drawn over the first
+	r, _, b := colorAt(is, frames[1].Data, 2, 2)
+	is.True(b > 200 && r < 50)
+	r, _, _ = colorAt(is, frames[1].Data, 20, 20)
+	is.True(r > 200)
+	// the second frame is disposed before the third
+	r, _, b = colorAt(is, frames[2].Data, 2, 2)
+	is.True(r > 200 && b < 50)
+	_, _, b = colorAt(is, frames[2].Data, 28, 28)
+	is.True(b > 200)
+}
+
+func TestCheckSampling(t *testing.T) {
+	is := is.New(t)
+	g := &gif.GIF{}
+	for i := 0; i < 10; i++ {
+		g.Image = append(g.Image, frame(image.Rect(0, 0, 8, 8), red))
+		g.Delay = append(g.Delay, 10)
+	}
+	check := func(indexes *[]int) gifutil.CheckFunc {
+		var lock sync.Mutex
+		return func(f gifutil.Frame) (bool, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			*indexes = append(*indexes, f.Index)
+			return false, nil
+		}
+	}
+	var indexes []int
+	_, _, err := gifutil.Check(context.Background(), encode(is, g), gifutil.Options{Every: 3, Concurrency: 1}, check(&indexes))
+	is.NoErr(err)
+	is.Equal(indexes, []int{0, 3, 6, 9})
+
+	indexes = nil
+	_, _, err = gifutil.Check(context.Background(), encode(is, g), gifutil.Options{Interval: 250 * time.Millisecond, Concurrency: 1}, check(&indexes))
+	is.NoErr(err)
+	is.Equal(indexes, []int{0, 3, 6, 9})
+
+	indexes = nil
+	_, _, err = gifutil.Check(context.Background(), encode(is, g), gifutil.Options{MaxFrames: 2, Concurrency: 1}, check(&indexes))
+	is.NoErr(err)
+	is.Equal(indexes, []int{0, 1})
+}
+
+func TestCheckStop(t *testing.T) {
+	is := is.New(t)
+	g := &gif.GIF{}
+	for i := 0; i < 10; i++ {
+		g.Image = append(g.Image, frame(image.Rect(0, 0, 8, 8), red))
+		g.Delay = append(g.Delay, 10)
+	}
+	var checked int
+	total, stopped, err := gifutil.Check(context.Background(), encode(is, g), gifutil.Options{Concurrency: 1}, func(f gifutil.Frame) (bool, error) {
+		checked++
+		return f.Index == 2, nil
+	})
+	is.NoErr(err)
+	is.Equal(total, 10)
+	is.Equal(stopped, true)
+	is.Equal(checked, 3)
+}
+
+func TestCheckNotGIF(t *testing.T) {
+	is := is.New(t)
+	_, _, err := gifutil.Check(context.Background(), bytes.NewReader([]byte("not a gif")), gifutil.Options{}, nil)
+	is.True(err != nil)
+}
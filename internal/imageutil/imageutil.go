@@ -0,0 +1,24 @@
+// Package imageutil provides helpers for working with images.
+package imageutil
+
+import (
+	"image"
+	"image/draw"
+)
+
+// SubImage gets the part of img inside r.
+// Images that support SubImage share their pixels with the result,
+// others are copied.
+func SubImage(img image.Image, r image.Rectangle) image.Image {
+	if r == img.Bounds() {
+		return img
+	}
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,33 @@
+package imageutil_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/machinebox/sdk-go/internal/imageutil"
+	"github.com/matryer/is"
+)
+
+// uniform is an image that does not support SubImage.
+type uniform struct {
+	*image.Uniform
+	bounds image.Rectangle
+}
+
+func (u uniform) Bounds() image.Rectangle { return u.bounds }
+
+func TestSubImage(t *testing.T) {
+	is := is.New(t)
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	img.Set(20, 10, color.White)
+	is.Equal(imageutil.SubImage(img, img.Bounds()), img)
+	sub := imageutil.SubImage(img, image.Rect(20, 10, 40, 30))
+	is.Equal(sub.Bounds(), image.Rect(20, 10, 40, 30))
+	is.Equal(sub.At(20, 10), color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	red := uniform{Uniform: image.NewUniform(color.RGBA{R: 255, A: 255}), bounds: image.Rect(0, 0, 100, 50)}
+	sub = imageutil.SubImage(red, image.Rect(20, 10, 40, 30))
+	is.Equal(sub.Bounds(), image.Rect(0, 0, 20, 20))
+	is.Equal(sub.At(0, 0), color.RGBA{R: 255, A: 255})
+}
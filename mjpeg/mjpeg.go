@@ -0,0 +1,135 @@
+// Package mjpeg reads frames from MJPEG (multipart/x-mixed-replace)
+// streams, such as those provided by many IP cameras, and sends them
+// to boxes.
+package mjpeg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Frame is a single image from an MJPEG stream.
+type Frame struct {
+	// Seq is the sequence number of the frame in the stream,
+	// starting at 1.
+	Seq int
+	// Time is when the frame was read.
+	Time time.Time
+	// Data is the JPEG image data.
+	Data []byte
+}
+
+// Reader reads frames from an MJPEG stream.
+type Reader struct {
+	r        *bufio.Reader
+	boundary string
+	mr       *multipart.Reader
+	closer   io.Closer
+	seq      int
+}
+
+// NewReader makes a new Reader that reads frames from r.
+// If boundary is empty, it is detected from the stream.
+func NewReader(r io.Reader, boundary string) *Reader {
+	return &Reader{
+		r:        bufio.NewReader(r),
+		boundary: boundary,
+	}
+}
+
+// Open opens the MJPEG stream at the specified URL.
+// If client is nil, http.DefaultClient is used; note that the
+// client's Timeout applies to the whole stream.
+// Clients must call Close.
+func Open(client *http.Client, streamURL *url.URL) (*Reader, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if !streamURL.IsAbs() {
+		return nil, errors.New("url must be absolute")
+	}
+	resp, err := client.Get(streamURL.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, errors.Errorf("mjpeg: %s", resp.Status)
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		resp.Body.Close()
+		return nil, errors.Errorf("mjpeg: unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	r := NewReader(resp.Body, strings.TrimPrefix(params["boundary"], "--"))
+	r.closer = resp.Body
+	return r, nil
+}
+
+// Close closes the stream if it was opened with Open.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// NextFrame reads the next frame from the stream.
+// It returns io.EOF when the stream ends.
+func (r *Reader) NextFrame() (Frame, error) {
+	if r.mr == nil {
+		if r.boundary == "" {
+			boundary, err := detectBoundary(r.r)
+			if err != nil {
+				return Frame{}, err
+			}
+			r.boundary = boundary
+		}
+		r.mr = multipart.NewReader(r.r, r.boundary)
+	}
+	for {
+		part, err := r.mr.NextPart()
+		if err != nil {
+			return Frame{}, err
+		}
+		data, err := ioutil.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return Frame{}, errors.Wrap(err, "read frame")
+		}
+		if len(data) == 0 {
+			continue
+		}
+		r.seq++
+		return Frame{Seq: r.seq, Time: time.Now(), Data: data}, nil
+	}
+}
+
+// detectBoundary finds the boundary from the first boundary line
+// in the stream, without consuming it.
+func detectBoundary(r *bufio.Reader) (string, error) {
+	for size := 64; ; size *= 2 {
+		b, err := r.Peek(size)
+		if i := bytes.Index(b, []byte("--")); i >= 0 {
+			if end := bytes.IndexAny(b[i:], "\r\n"); end >= 0 {
+				return string(bytes.TrimSpace(b[i+2 : i+end])), nil
+			}
+		}
+		if err != nil {
+			if err == io.EOF || err == bufio.ErrBufferFull {
+				return "", errors.New("mjpeg: boundary not found")
+			}
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package mjpeg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/objectbox"
+)
+
+// Result is the outcome of checking a single frame.
+type Result struct {
+	// Frame is the frame that was checked.
+	Frame Frame
+	// Faces are the faces found by Facebox.
+	Faces []facebox.Face
+	// Objects are the objects found by Objectbox.
+	Objects *objectbox.CheckResponse
+	// Err is the error checking the frame, or reading the stream.
+	Err error
+}
+
+// Checker checks an image, storing the outcome in result.
+type Checker func(image io.Reader, result *Result) error
+
+// Facebox makes a Checker that checks frames for faces.
+func Facebox(client *facebox.Client) Checker {
+	return func(image io.Reader, result *Result) error {
+		faces, err := client.Check(image)
+		if err != nil {
+			return err
+		}
+		result.Faces = faces
+		return nil
+	}
+}
+
+// Objectbox makes a Checker that checks frames for objects.
+func Objectbox(client *objectbox.Client) Checker {
+	return func(image io.Reader, result *Result) error {
+		objects, err := client.Check(image)
+		if err != nil {
+			return err
+		}
+		result.Objects = &objects
+		return nil
+	}
+}
+
+// ProcessOptions control the behaviour of Process.
+type ProcessOptions struct {
+	// Interval is the minimum time between sampled frames.
+	// Zero means every frame is sampled.
+	Interval time.Duration
+	// Concurrency is the maximum number of frames that will be
+	// checked at the same time. Defaults to 2.
+	Concurrency int
+	// MaxAge is the age at which a frame waiting to be checked
+	// is dropped. Zero means frames are only dropped when more than
+	// Concurrency frames are waiting.
+	MaxAge time.Duration
+}
+
+// Process reads frames from the Reader until it ends or the context
+// is cancelled, and checks the sampled frames, sending each result on
+// the returned channel. The channel is closed when processing has
+// finished.
+// When frames arrive faster than they can be checked, the oldest
+// waiting frames are dropped so results stay current. Results may be
+// sent out of order; use Frame.Seq to order them.
+// If reading the stream fails, a final Result with Err set and an
+// empty Frame is sent.
+// When the context is cancelled, the Reader is closed so a read
+// waiting on a stalled stream returns. A Reader made with NewReader
+// can not be closed this way; close the underlying io.Reader to stop
+// such a read.
+func Process(ctx context.Context, r *Reader, check Checker, options *ProcessOptions) <-chan Result {
+	var o ProcessOptions
+	if options != nil {
+		o = *options
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 2
+	}
+	results := make(chan Result)
+	pending := make(chan Frame, o.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < o.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for frame := range pending {
+				if o.MaxAge > 0 && time.Since(frame.Time) > o.MaxAge {
+					continue
+				}
+				result := Result{Frame: frame}
+				result.Err = check(bytes.NewReader(frame.Data), &result)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// unblock a read waiting on the network
+			r.Close()
+		case <-done:
+		}
+	}()
+	go func() {
+		defer func() {
+			close(done)
+			wg.Wait()
+			close(results)
+		}()
+		var lastSampled time.Time
+		for {
+			if ctx.Err() != nil {
+				close(pending)
+				return
+			}
+			frame, err := r.NextFrame()
+			if err != nil {
+				close(pending)
+				if err == io.EOF || ctx.Err() != nil {
+					return
+				}
+				wg.Wait()
+				select {
+				case results <- Result{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if o.Interval > 0 && !lastSampled.IsZero() && frame.Time.Sub(lastSampled) < o.Interval {
+				continue
+			}
+			lastSampled = frame.Time
+			for {
+				select {
+				case pending <- frame:
+				default:
+					// drop the oldest waiting frame to make room
+					select {
+					case <-pending:
+					default:
+					}
+					continue
+				}
+				break
+			}
+		}
+	}()
+	return results
+}
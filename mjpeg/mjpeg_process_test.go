@@ -0,0 +1,159 @@
+package mjpeg_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/mjpeg"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func TestProcessFacebox(t *testing.T) {
+	is := is.New(t)
+	stream := newStreamServer([]string{"frame1", "frame2", "frame3", "frame4"})
+	defer stream.Close()
+	box := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		if string(b) == "frame3" {
+			io.WriteString(w, `{"success": false, "error": "something went wrong"}`)
+			return
+		}
+		io.WriteString(w, `{"success": true, "faces": [{"name": "`+string(b)+`", "matched": true}]}`)
+	}))
+	defer box.Close()
+	streamURL, err := url.Parse(stream.URL)
+	is.NoErr(err)
+	r, err := mjpeg.Open(nil, streamURL)
+	is.NoErr(err)
+	defer r.Close()
+
+	var results []mjpeg.Result
+	for result := range mjpeg.Process(context.Background(), r, mjpeg.Facebox(facebox.New(box.URL)), &mjpeg.ProcessOptions{
+		Concurrency: 4,
+	}) {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Frame.Seq < results[j].Frame.Seq
+	})
+	// frames may be dropped if they arrive faster than they are checked
+	is.True(len(results) > 0)
+	for _, result := range results {
+		name := "frame" + strconv.Itoa(result.Frame.Seq)
+		if name == "frame3" {
+			is.Equal(result.Err.Error(), "facebox: something went wrong")
+			continue
+		}
+		is.NoErr(result.Err)
+		is.Equal(result.Faces[0].Name, name)
+	}
+}
+
+func TestProcessObjectboxInterval(t *testing.T) {
+	is := is.New(t)
+	stream := newStreamServer([]string{"frame1", "frame2", "frame3"})
+	defer stream.Close()
+	box := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/objectbox/check")
+		io.WriteString(w, `{"success": true, "detectors": [{"id": "cars", "objects": [{"score": 0.9}]}]}`)
+	}))
+	defer box.Close()
+	streamURL, err := url.Parse(stream.URL)
+	is.NoErr(err)
+	r, err := mjpeg.Open(nil, streamURL)
+	is.NoErr(err)
+	defer r.Close()
+
+	var results []mjpeg.Result
+	for result := range mjpeg.Process(context.Background(), r, mjpeg.Objectbox(objectbox.New(box.URL)), &mjpeg.ProcessOptions{
+		Interval: time.Hour,
+	}) {
+		results = append(results, result)
+	}
+	is.Equal(len(results), 1)
+	is.NoErr(results[0].Err)
+	is.Equal(results[0].Frame.Seq, 1)
+	is.Equal(results[0].Objects.Detectors[0].ID, "cars")
+}
+
+func TestProcessDropsStaleFrames(t *testing.T) {
+	is := is.New(t)
+	frames := make([]string, 50)
+	for i := range frames {
+		frames[i] = strings.Repeat("x", i+1)
+	}
+	var stream strings.Builder
+	writeStream(&stream, "frame", frames)
+	r := mjpeg.NewReader(strings.NewReader(stream.String()), "frame")
+	slow := func(image io.Reader, result *mjpeg.Result) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+	var count int
+	var last int
+	for result := range mjpeg.Process(context.Background(), r, slow, &mjpeg.ProcessOptions{Concurrency: 1}) {
+		is.NoErr(result.Err)
+		count++
+		last = result.Frame.Seq
+	}
+	is.True(count < len(frames))
+	is.Equal(last, len(frames))
+}
+
+func TestProcessCancelStalledStream(t *testing.T) {
+	is := is.New(t)
+	stalled := make(chan struct{})
+	defer close(stalled)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=frame")
+		io.WriteString(w, "--frame\r\nContent-Type: image/jpeg\r\nContent-Length: 6\r\n\r\nframe1\r\n--frame\r\n")
+		w.(http.Flusher).Flush()
+		// the camera stops sending
+		select {
+		case <-stalled:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	streamURL, err := url.Parse(srv.URL)
+	is.NoErr(err)
+	r, err := mjpeg.Open(nil, streamURL)
+	is.NoErr(err)
+	defer r.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	checked := make(chan struct{}, 1)
+	check := func(image io.Reader, result *mjpeg.Result) error {
+		checked <- struct{}{}
+		return nil
+	}
+	results := mjpeg.Process(ctx, r, check, nil)
+	<-checked
+	cancel()
+	finished := make(chan struct{})
+	go func() {
+		for range results {
+		}
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Process did not finish after the context was cancelled")
+	}
+}
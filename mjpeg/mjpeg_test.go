@@ -0,0 +1,94 @@
+package mjpeg_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/mjpeg"
+	"github.com/matryer/is"
+)
+
+// writeStream writes the frames as an MJPEG stream.
+func writeStream(w io.Writer, boundary string, frames []string) {
+	for _, frame := range frames {
+		fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n%s\r\n", boundary, len(frame), frame)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	fmt.Fprintf(w, "--%s--\r\n", boundary)
+}
+
+// newStreamServer makes a local MJPEG server that streams the frames.
+func newStreamServer(frames []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=frame")
+		writeStream(w, "frame", frames)
+	}))
+}
+
+func TestOpen(t *testing.T) {
+	is := is.New(t)
+	srv := newStreamServer([]string{"frame1", "frame2", "frame3"})
+	defer srv.Close()
+	streamURL, err := url.Parse(srv.URL)
+	is.NoErr(err)
+	r, err := mjpeg.Open(nil, streamURL)
+	is.NoErr(err)
+	defer r.Close()
+	for i := 1; i <= 3; i++ {
+		frame, err := r.NextFrame()
+		is.NoErr(err)
+		is.Equal(frame.Seq, i)
+		is.Equal(string(frame.Data), fmt.Sprintf("frame%d", i))
+		is.True(!frame.Time.IsZero())
+	}
+	_, err = r.NextFrame()
+	is.Equal(err, io.EOF)
+}
+
+func TestOpenNotMJPEG(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html></html>")
+	}))
+	defer srv.Close()
+	streamURL, err := url.Parse(srv.URL)
+	is.NoErr(err)
+	_, err = mjpeg.Open(nil, streamURL)
+	is.Equal(err.Error(), `mjpeg: unexpected content type "text/html"`)
+}
+
+func TestNewReaderDetectBoundary(t *testing.T) {
+	is := is.New(t)
+	var stream strings.Builder
+	writeStream(&stream, "myboundary", []string{"frame1", "frame2"})
+	r := mjpeg.NewReader(strings.NewReader(stream.String()), "")
+	frame, err := r.NextFrame()
+	is.NoErr(err)
+	is.Equal(string(frame.Data), "frame1")
+	frame, err = r.NextFrame()
+	is.NoErr(err)
+	is.Equal(string(frame.Data), "frame2")
+	_, err = r.NextFrame()
+	is.Equal(err, io.EOF)
+}
+
+func TestOpenNotSuccess(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=frame")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+	streamURL, err := url.Parse(srv.URL)
+	is.NoErr(err)
+	_, err = mjpeg.Open(nil, streamURL)
+	is.Equal(err.Error(), "mjpeg: 304 Not Modified")
+}
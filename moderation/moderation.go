@@ -0,0 +1,207 @@
+// Package moderation decides whether images are acceptable by
+// checking them with Nudebox, Tagbox and Objectbox, and applying
+// a Policy to the results.
+package moderation
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/pkg/errors"
+)
+
+// Moderator checks images against a Policy.
+// Only the boxes used by the rules in the policy are called.
+type Moderator struct {
+	// Policy is the policy that decides the verdict.
+	Policy *Policy
+	// Nudebox is the client used for SignalNude rules.
+	Nudebox *nudebox.Client
+	// Tagbox is the client used for SignalTag and SignalCustomTag rules.
+	Tagbox *tagbox.Client
+	// Objectbox is the client used for SignalObject rules.
+	Objectbox *objectbox.Client
+}
+
+// Result is the outcome of moderating an image.
+type Result struct {
+	// Verdict is the most severe verdict of the matched rules, or
+	// the policy default if none matched.
+	Verdict Verdict `json:"verdict"`
+	// Evidence are the matches that led to the verdict, most
+	// severe first.
+	Evidence []Evidence `json:"evidence"`
+	// Nude is the nudity probability, if Nudebox was called.
+	Nude *float64 `json:"nude,omitempty"`
+	// Tags are the tags, if Tagbox was called.
+	Tags *tagbox.CheckResponse `json:"tags,omitempty"`
+	// Objects are the objects, if Objectbox was called.
+	Objects *objectbox.CheckResponse `json:"objects,omitempty"`
+	// Errors are the errors from boxes that failed, by box name.
+	// When a box fails, the verdict is at least Policy.OnError.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Evidence is a rule that matched.
+type Evidence struct {
+	// Rule is the rule that matched.
+	Rule Rule `json:"rule"`
+	// Value is the value that matched the rule.
+	Value float64 `json:"value"`
+	// Rect is where the object is, for SignalObject rules.
+	Rect *objectbox.Rect `json:"rect,omitempty"`
+}
+
+// Check moderates the image.
+// Check only returns an error if the image could not be read or the
+// moderator is not set up correctly; errors from boxes are reported in
+// Result.Errors.
+func (m *Moderator) Check(image io.Reader) (*Result, error) {
+	if m.Policy == nil {
+		return nil, errors.New("moderation: missing policy")
+	}
+	if err := m.Policy.Validate(); err != nil {
+		return nil, errors.Wrap(err, "moderation")
+	}
+	useNudebox := m.Policy.uses(SignalNude)
+	useTagbox := m.Policy.uses(SignalTag, SignalCustomTag)
+	useObjectbox := m.Policy.uses(SignalObject)
+	switch {
+	case useNudebox && m.Nudebox == nil:
+		return nil, errors.New("moderation: policy needs Nudebox")
+	case useTagbox && m.Tagbox == nil:
+		return nil, errors.New("moderation: policy needs Tagbox")
+	case useObjectbox && m.Objectbox == nil:
+		return nil, errors.New("moderation: policy needs Objectbox")
+	}
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	result := &Result{}
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+	)
+	run := func(box string, check func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := check(); err != nil {
+				lock.Lock()
+				defer lock.Unlock()
+				if result.Errors == nil {
+					result.Errors = make(map[string]string)
+				}
+				result.Errors[box] = err.Error()
+			}
+		}()
+	}
+	if useNudebox {
+		run("nudebox", func() error {
+			nude, err := m.Nudebox.Check(bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			result.Nude = &nude
+			return nil
+		})
+	}
+	if useTagbox {
+		run("tagbox", func() error {
+			tags, err := m.Tagbox.Check(bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			result.Tags = &tags
+			return nil
+		})
+	}
+	if useObjectbox {
+		run("objectbox", func() error {
+			objects, err := m.Objectbox.Check(bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			result.Objects = &objects
+			return nil
+		})
+	}
+	wg.Wait()
+	m.Policy.decide(result)
+	return result, nil
+}
+
+// decide applies the policy to the box results in result.
+func (p *Policy) decide(result *Result) {
+	result.Evidence = []Evidence{}
+	for _, rule := range p.Rules {
+		result.Evidence = append(result.Evidence, rule.evidence(result)...)
+	}
+	sort.SliceStable(result.Evidence, func(i, j int) bool {
+		return result.Evidence[i].Rule.Verdict.severity() > result.Evidence[j].Rule.Verdict.severity()
+	})
+	result.Verdict = p.Default
+	if result.Verdict == "" {
+		result.Verdict = Allow
+	}
+	if len(result.Evidence) > 0 {
+		result.Verdict = result.Evidence[0].Rule.Verdict
+	}
+	if len(result.Errors) > 0 {
+		onError := p.OnError
+		if onError == "" {
+			onError = Review
+		}
+		if onError.severity() > result.Verdict.severity() {
+			result.Verdict = onError
+		}
+	}
+}
+
+// evidence gets the matches of the rule in the box results.
+func (r Rule) evidence(result *Result) []Evidence {
+	var evidence []Evidence
+	switch r.Signal {
+	case SignalNude:
+		if result.Nude != nil && r.matches(*result.Nude) {
+			evidence = append(evidence, Evidence{Rule: r, Value: *result.Nude})
+		}
+	case SignalTag, SignalCustomTag:
+		if result.Tags == nil {
+			break
+		}
+		tags := result.Tags.Tags
+		if r.Signal == SignalCustomTag {
+			tags = result.Tags.CustomTags
+		}
+		for _, tag := range tags {
+			if strings.EqualFold(tag.Tag, r.Name) && r.matches(tag.Confidence) {
+				evidence = append(evidence, Evidence{Rule: r, Value: tag.Confidence})
+			}
+		}
+	case SignalObject:
+		if result.Objects == nil {
+			break
+		}
+		for _, detector := range result.Objects.Detectors {
+			if r.Name != "" && r.Name != detector.ID && r.Name != detector.Name {
+				continue
+			}
+			for _, object := range detector.Objects {
+				if r.matches(object.Score) {
+					rect := object.Rect
+					evidence = append(evidence, Evidence{Rule: r, Value: object.Score, Rect: &rect})
+				}
+			}
+		}
+	}
+	return evidence
+}
@@ -0,0 +1,156 @@
+package moderation
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Verdict is the outcome of moderating an image.
+type Verdict string
+
+const (
+	// Allow means the image is acceptable.
+	Allow Verdict = "allow"
+	// Review means the image should be checked by a person.
+	Review Verdict = "review"
+	// Reject means the image is not acceptable.
+	Reject Verdict = "reject"
+)
+
+// severity orders verdicts from least to most severe.
+func (v Verdict) severity() int {
+	switch v {
+	case Allow:
+		return 1
+	case Review:
+		return 2
+	case Reject:
+		return 3
+	}
+	return 0
+}
+
+// Signal is what a Rule looks at.
+type Signal string
+
+const (
+	// SignalNude is the nudity probability from Nudebox.
+	SignalNude Signal = "nude"
+	// SignalTag is the confidence of a tag from Tagbox.
+	SignalTag Signal = "tag"
+	// SignalCustomTag is the confidence of a custom tag from Tagbox.
+	SignalCustomTag Signal = "custom_tag"
+	// SignalObject is the score of an object from Objectbox.
+	SignalObject Signal = "object"
+)
+
+// Policy decides the verdict for an image.
+//
+// Policies are usually loaded from JSON:
+//
+//	{
+//		"rules": [
+//			{"signal": "nude", "op": ">", "threshold": 0.8, "verdict": "reject"},
+//			{"signal": "nude", "op": ">", "threshold": 0.5, "verdict": "review"},
+//			{"signal": "tag", "name": "weapon", "op": ">", "threshold": 0.6, "verdict": "review"},
+//			{"signal": "object", "name": "guns", "op": ">=", "threshold": 0.7, "verdict": "reject"}
+//		]
+//	}
+type Policy struct {
+	// Rules are the rules that are checked.
+	Rules []Rule `json:"rules"`
+	// Default is the verdict when no rules match.
+	// Defaults to Allow.
+	Default Verdict `json:"default,omitempty"`
+	// OnError is the least severe verdict when a box fails.
+	// Defaults to Review.
+	OnError Verdict `json:"on_error,omitempty"`
+}
+
+// Rule matches when a signal compares with the threshold.
+type Rule struct {
+	// Description describes the rule, for the evidence.
+	Description string `json:"description,omitempty"`
+	// Signal is what the rule looks at.
+	Signal Signal `json:"signal"`
+	// Name is the tag for SignalTag and SignalCustomTag, compared
+	// case insensitively, and the detector ID or name for
+	// SignalObject. For SignalObject, empty matches any detector.
+	Name string `json:"name,omitempty"`
+	// Op is the comparison: ">", ">=", "<" or "<=".
+	// Defaults to ">".
+	Op string `json:"op,omitempty"`
+	// Threshold is the value the signal is compared with.
+	Threshold float64 `json:"threshold"`
+	// Verdict is the verdict when the rule matches.
+	Verdict Verdict `json:"verdict"`
+}
+
+// matches checks whether the value matches the rule.
+func (r Rule) matches(value float64) bool {
+	switch r.Op {
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	}
+	return value > r.Threshold
+}
+
+// LoadPolicy reads a JSON Policy.
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	var policy Policy
+	if err := json.NewDecoder(r).Decode(&policy); err != nil {
+		return nil, errors.Wrap(err, "decode policy")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks that the policy is valid.
+func (p *Policy) Validate() error {
+	for _, verdict := range []Verdict{p.Default, p.OnError} {
+		if verdict != "" && verdict.severity() == 0 {
+			return errors.Errorf("unknown verdict %q", verdict)
+		}
+	}
+	for i, rule := range p.Rules {
+		if rule.Verdict.severity() == 0 {
+			return errors.Errorf("rule %d: unknown verdict %q", i, rule.Verdict)
+		}
+		switch rule.Op {
+		case "", ">", ">=", "<", "<=":
+		default:
+			return errors.Errorf("rule %d: unknown op %q", i, rule.Op)
+		}
+		switch rule.Signal {
+		case SignalNude, SignalObject:
+		case SignalTag, SignalCustomTag:
+			if strings.TrimSpace(rule.Name) == "" {
+				return errors.Errorf("rule %d: name is required for %s", i, rule.Signal)
+			}
+		default:
+			return errors.Errorf("rule %d: unknown signal %q", i, rule.Signal)
+		}
+	}
+	return nil
+}
+
+// uses checks whether any rule looks at one of the signals.
+func (p *Policy) uses(signals ...Signal) bool {
+	for _, rule := range p.Rules {
+		for _, signal := range signals {
+			if rule.Signal == signal {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package moderation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/moderation"
+	"github.com/matryer/is"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	is := is.New(t)
+	policy, err := moderation.LoadPolicy(strings.NewReader(testPolicy))
+	is.NoErr(err)
+	is.Equal(len(policy.Rules), 4)
+	is.Equal(policy.Rules[0].Signal, moderation.SignalNude)
+	is.Equal(policy.Rules[0].Verdict, moderation.Reject)
+	is.Equal(policy.Rules[2].Name, "weapon")
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	is := is.New(t)
+	for policy, expected := range map[string]string{
+		`{"rules": [{"signal": "nude", "threshold": 0.8, "verdict": "block"}]}`:  `rule 0: unknown verdict "block"`,
+		`{"rules": [{"signal": "face", "threshold": 0.8, "verdict": "reject"}]}`: `rule 0: unknown signal "face"`,
+		`{"rules": [{"signal": "nude", "op": "==", "verdict": "reject"}]}`:       `rule 0: unknown op "=="`,
+		`{"rules": [{"signal": "tag", "threshold": 0.8, "verdict": "review"}]}`:  `rule 0: name is required for tag`,
+		`{"default": "maybe"}`: `unknown verdict "maybe"`,
+	} {
+		_, err := moderation.LoadPolicy(strings.NewReader(policy))
+		is.True(err != nil)
+		is.Equal(err.Error(), expected)
+	}
+	_, err := moderation.LoadPolicy(strings.NewReader(`{`))
+	is.True(err != nil)
+}
@@ -0,0 +1,150 @@
+package moderation_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/machinebox/sdk-go/moderation"
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+const testPolicy = `{
+	"rules": [
+		{"signal": "nude", "op": ">", "threshold": 0.8, "verdict": "reject"},
+		{"signal": "nude", "op": ">", "threshold": 0.5, "verdict": "review"},
+		{"signal": "tag", "name": "weapon", "op": ">", "threshold": 0.6, "verdict": "review"},
+		{"signal": "object", "name": "guns", "op": ">=", "threshold": 0.7, "verdict": "reject"}
+	]
+}`
+
+// newBoxes makes a server that acts as Nudebox, Tagbox and Objectbox.
+// The image data is a simple description of what the boxes find.
+func newBoxes(is *is.I, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		image := string(b)
+		switch r.URL.Path {
+		case "/nudebox/check":
+			if image == "nude" {
+				io.WriteString(w, `{"success": true, "nude": 0.95}`)
+				return
+			}
+			if image == "error" {
+				io.WriteString(w, `{"success": false, "error": "something went wrong"}`)
+				return
+			}
+			io.WriteString(w, `{"success": true, "nude": 0.1}`)
+		case "/tagbox/check":
+			if image == "weapon" {
+				io.WriteString(w, `{"success": true, "tags": [{"tag": "Weapon", "confidence": 0.7}, {"tag": "table", "confidence": 0.9}]}`)
+				return
+			}
+			io.WriteString(w, `{"success": true, "tags": [{"tag": "table", "confidence": 0.9}]}`)
+		case "/objectbox/check":
+			if image == "weapon" {
+				io.WriteString(w, `{"success": true, "detectors": [{"id": "det1", "name": "guns", "objects": [
+					{"rect": {"top": 1, "left": 2, "width": 3, "height": 4}, "score": 0.8},
+					{"rect": {"top": 5, "left": 6, "width": 7, "height": 8}, "score": 0.2}
+				]}]}`)
+				return
+			}
+			io.WriteString(w, `{"success": true, "detectors": [{"id": "det1", "name": "guns", "objects": []}]}`)
+		default:
+			is.Fail() // unexpected path
+		}
+	}))
+}
+
+func newModerator(is *is.I, addr string) *moderation.Moderator {
+	policy, err := moderation.LoadPolicy(strings.NewReader(testPolicy))
+	is.NoErr(err)
+	return &moderation.Moderator{
+		Policy:    policy,
+		Nudebox:   nudebox.New(addr),
+		Tagbox:    tagbox.New(addr),
+		Objectbox: objectbox.New(addr),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	var calls int32
+	srv := newBoxes(is, &calls)
+	defer srv.Close()
+	m := newModerator(is, srv.URL)
+
+	res, err := m.Check(strings.NewReader("kittens"))
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(&calls), int32(3))
+	is.Equal(res.Verdict, moderation.Allow)
+	is.Equal(len(res.Evidence), 0)
+	is.Equal(*res.Nude, 0.1)
+	is.Equal(len(res.Tags.Tags), 1)
+
+	res, err = m.Check(strings.NewReader("nude"))
+	is.NoErr(err)
+	is.Equal(res.Verdict, moderation.Reject)
+	is.Equal(len(res.Evidence), 2)
+	is.Equal(res.Evidence[0].Rule.Verdict, moderation.Reject)
+	is.Equal(res.Evidence[0].Value, 0.95)
+	is.Equal(res.Evidence[1].Rule.Verdict, moderation.Review)
+
+	res, err = m.Check(strings.NewReader("weapon"))
+	is.NoErr(err)
+	is.Equal(res.Verdict, moderation.Reject)
+	is.Equal(len(res.Evidence), 2)
+	is.Equal(res.Evidence[0].Rule.Signal, moderation.SignalObject)
+	is.Equal(res.Evidence[0].Value, 0.8)
+	is.Equal(*res.Evidence[0].Rect, objectbox.Rect{Top: 1, Left: 2, Width: 3, Height: 4})
+	is.Equal(res.Evidence[1].Rule.Signal, moderation.SignalTag)
+	is.Equal(res.Evidence[1].Value, 0.7)
+}
+
+func TestCheckBoxError(t *testing.T) {
+	is := is.New(t)
+	var calls int32
+	srv := newBoxes(is, &calls)
+	defer srv.Close()
+	m := newModerator(is, srv.URL)
+	res, err := m.Check(strings.NewReader("error"))
+	is.NoErr(err)
+	is.Equal(res.Verdict, moderation.Review)
+	is.Equal(res.Errors["nudebox"], "nudebox: something went wrong")
+	is.Equal(res.Nude, nil)
+}
+
+func TestCheckOnlyCallsNeededBoxes(t *testing.T) {
+	is := is.New(t)
+	var calls int32
+	srv := newBoxes(is, &calls)
+	defer srv.Close()
+	m := &moderation.Moderator{
+		Policy: &moderation.Policy{
+			Rules: []moderation.Rule{
+				{Signal: moderation.SignalNude, Threshold: 0.5, Verdict: moderation.Reject},
+			},
+		},
+		Nudebox: nudebox.New(srv.URL),
+	}
+	res, err := m.Check(strings.NewReader("nude"))
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+	is.Equal(res.Verdict, moderation.Reject)
+	is.Equal(res.Tags, nil)
+
+	m.Policy.Rules = append(m.Policy.Rules, moderation.Rule{Signal: moderation.SignalObject, Verdict: moderation.Review})
+	_, err = m.Check(strings.NewReader("nude"))
+	is.Equal(err.Error(), "moderation: policy needs Objectbox")
+}
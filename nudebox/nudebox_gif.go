@@ -0,0 +1,106 @@
+package nudebox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/machinebox/sdk-go/internal/gifutil"
+)
+
+// GIFOptions control how the frames of an animated GIF are checked.
+type GIFOptions struct {
+	// Every checks every Nth frame. Defaults to 1.
+	Every int
+	// Interval is the minimum time between checked frames.
+	// If set, Every is ignored.
+	Interval time.Duration
+	// MaxFrames is the maximum number of frames that will be
+	// checked. Defaults to 50.
+	MaxFrames int
+	// Concurrency is the maximum number of frames that will be
+	// checked at the same time. Defaults to 4.
+	Concurrency int
+	// Threshold stops checking once a frame has a nudity probability
+	// at or above it. Zero checks every sampled frame.
+	Threshold float64
+}
+
+// GIFResult is the outcome of checking an animated GIF.
+type GIFResult struct {
+	// Frames are the scores of the checked frames, in order.
+	Frames []FrameScore `json:"frames"`
+	// TotalFrames is the number of frames in the GIF.
+	TotalFrames int `json:"total_frames"`
+	// Max is the highest nudity probability of the checked frames.
+	Max float64 `json:"max"`
+	// Mean is the mean nudity probability of the checked frames.
+	Mean float64 `json:"mean"`
+	// Stopped is true if checking stopped early because a frame
+	// reached GIFOptions.Threshold.
+	Stopped bool `json:"stopped"`
+}
+
+// FrameScore is the nudity probability of a single frame.
+type FrameScore struct {
+	// Frame is the index of the frame, starting at 0.
+	Frame int `json:"frame"`
+	// Offset is when the frame is shown.
+	Offset time.Duration `json:"offset"`
+	// Nude is the nudity probability.
+	Nude float64 `json:"nude"`
+}
+
+// CheckGIF checks the frames of the animated GIF. Nudebox only sees
+// the first frame of a GIF passed to Check, so CheckGIF decodes the
+// frames and checks them individually.
+// When checking stops early, only the frames checked so far are
+// in the result.
+func (c *Client) CheckGIF(ctx context.Context, image io.Reader, options *GIFOptions) (*GIFResult, error) {
+	if options == nil {
+		options = &GIFOptions{}
+	}
+	result := &GIFResult{}
+	var lock sync.Mutex
+	total, stopped, err := gifutil.Check(ctx, image, gifutil.Options{
+		Every:       options.Every,
+		Interval:    options.Interval,
+		MaxFrames:   options.MaxFrames,
+		Concurrency: options.Concurrency,
+	}, func(frame gifutil.Frame) (bool, error) {
+		nude, err := c.Check(bytes.NewReader(frame.Data))
+		if err != nil {
+			return false, err
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		result.Frames = append(result.Frames, FrameScore{
+			Frame:  frame.Index,
+			Offset: frame.Offset,
+			Nude:   nude,
+		})
+		return options.Threshold > 0 && nude >= options.Threshold, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.TotalFrames = total
+	result.Stopped = stopped
+	sort.Slice(result.Frames, func(i, j int) bool {
+		return result.Frames[i].Frame < result.Frames[j].Frame
+	})
+	var sum float64
+	for _, frame := range result.Frames {
+		sum += frame.Nude
+		if frame.Nude > result.Max {
+			result.Max = frame.Nude
+		}
+	}
+	if len(result.Frames) > 0 {
+		result.Mean = sum / float64(len(result.Frames))
+	}
+	return result, nil
+}
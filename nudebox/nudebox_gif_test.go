@@ -0,0 +1,109 @@
+package nudebox_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/matryer/is"
+)
+
+// makeGIF makes an animated GIF with a frame for each color.
+func makeGIF(is *is.I, colors ...color.Color) *bytes.Buffer {
+	g := &gif.GIF{}
+	for _, c := range colors {
+		img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette.Plan9)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(img.Palette.Index(c))
+		}
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 50)
+	}
+	var buf bytes.Buffer
+	is.NoErr(gif.EncodeAll(&buf, g))
+	return &buf
+}
+
+// newGIFServer makes a Nudebox that reports red frames as nude.
+func newGIFServer(is *is.I) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/nudebox/check")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		img, err := jpeg.Decode(f)
+		is.NoErr(err)
+		red, green, _, _ := img.At(4, 4).RGBA()
+		nude := 0.1
+		if red > 0xc000 && green < 0x4000 {
+			nude = 0.9
+		}
+		fmt.Fprintf(w, `{"success": true, "nude": %v}`, nude)
+	}))
+}
+
+func TestCheckGIF(t *testing.T) {
+	is := is.New(t)
+	srv := newGIFServer(is)
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	red, white := color.RGBA{R: 255, A: 255}, color.White
+	res, err := nb.CheckGIF(context.Background(), makeGIF(is, white, white, red, white), nil)
+	is.NoErr(err)
+	is.Equal(res.TotalFrames, 4)
+	is.Equal(len(res.Frames), 4)
+	is.Equal(res.Frames[2].Frame, 2)
+	is.Equal(res.Frames[2].Offset, time.Second)
+	is.Equal(res.Frames[2].Nude, 0.9)
+	is.Equal(res.Frames[3].Nude, 0.1)
+	is.Equal(res.Max, 0.9)
+	is.True(math.Abs(res.Mean-0.3) < 1e-9)
+	is.Equal(res.Stopped, false)
+
+	res, err = nb.CheckGIF(context.Background(), makeGIF(is, white, white, red, white), &nudebox.GIFOptions{
+		Every: 2,
+	})
+	is.NoErr(err)
+	is.Equal(len(res.Frames), 2)
+	is.Equal(res.Max, 0.9)
+}
+
+func TestCheckGIFThreshold(t *testing.T) {
+	is := is.New(t)
+	srv := newGIFServer(is)
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	red, white := color.RGBA{R: 255, A: 255}, color.White
+	res, err := nb.CheckGIF(context.Background(), makeGIF(is, white, red, white, white, white, white), &nudebox.GIFOptions{
+		Concurrency: 1,
+		Threshold:   0.8,
+	})
+	is.NoErr(err)
+	is.Equal(res.TotalFrames, 6)
+	is.Equal(res.Stopped, true)
+	is.Equal(len(res.Frames), 2)
+	is.Equal(res.Max, 0.9)
+}
+
+func TestCheckGIFError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": false, "error": "something went wrong"}`))
+	}))
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	_, err := nb.CheckGIF(context.Background(), makeGIF(is, color.White), nil)
+	is.True(err != nil)
+	is.Equal(err.Error(), "frame 0: nudebox: something went wrong")
+}
@@ -0,0 +1,207 @@
+package nudebox
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif" // register the GIF decoder for previews
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder for previews
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// PreviewOptions control how previews are made.
+type PreviewOptions struct {
+	// Threshold is the nudity probability at or above which the
+	// preview is blurred. Defaults to 0.5.
+	Threshold float64
+	// MaxSize is the maximum width and height of the preview
+	// in pixels. Defaults to 256.
+	MaxSize int
+	// BlurSize is the size the image is reduced to before it is
+	// enlarged again to blur it. Smaller is blurrier.
+	// It is limited to a quarter of the width and height of the
+	// thumbnail. Defaults to 12.
+	BlurSize int
+	// Quality is the JPEG quality of the preview. Defaults to 80.
+	Quality int
+}
+
+// Preview is a thumbnail that is safe to show.
+type Preview struct {
+	// Nude is the nudity probability of the image.
+	Nude float64
+	// Blurred is true if the preview was blurred because Nude was
+	// at or above the threshold.
+	Blurred bool
+	// Image is the preview image.
+	Image image.Image
+	// JPEG is the preview image encoded as a JPEG.
+	JPEG []byte
+}
+
+// Preview checks the image, and makes a thumbnail of it that is
+// heavily blurred if the nudity probability is at or above
+// PreviewOptions.Threshold.
+func (c *Client) Preview(image io.Reader, options *PreviewOptions) (*Preview, error) {
+	if options == nil {
+		options = &PreviewOptions{}
+	}
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	maxSize := options.MaxSize
+	if maxSize < 1 {
+		maxSize = 256
+	}
+	blurSize := options.BlurSize
+	if blurSize < 1 {
+		blurSize = 12
+	}
+	quality := options.Quality
+	if quality < 1 {
+		quality = 80
+	}
+	data, err := ioutil.ReadAll(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "read image")
+	}
+	src, _, err := decodeImage(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode image")
+	}
+	nude, err := c.Check(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	preview := &Preview{
+		Nude:    nude,
+		Blurred: nude >= threshold,
+	}
+	width, height := fit(src.Bounds().Dx(), src.Bounds().Dy(), maxSize)
+	thumbnail := shrink(src, width, height)
+	if preview.Blurred {
+		// always reduce the thumbnail, even if BlurSize is bigger
+		// than it, otherwise it would not be blurred at all
+		blurSize = minInt(blurSize, maxInt(1, minInt(width, height)/4))
+		smallWidth, smallHeight := fit(width, height, blurSize)
+		thumbnail = enlarge(shrink(thumbnail, smallWidth, smallHeight), width, height)
+	}
+	preview.Image = thumbnail
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, errors.Wrap(err, "encode preview")
+	}
+	preview.JPEG = buf.Bytes()
+	return preview, nil
+}
+
+func decodeImage(data []byte) (image.Image, string, error) {
+	return image.Decode(bytes.NewReader(data))
+}
+
+// fit gets the dimensions that fit inside a square of size,
+// keeping the aspect ratio. Images that already fit are not enlarged.
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, maxInt(1, height*size/width)
+	}
+	return maxInt(1, width*size/height), size
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// toRGBA converts the image to RGBA, with bounds starting at 0,0.
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	return rgba
+}
+
+// shrink reduces the image to width x height by averaging the
+// pixels that make up each new pixel.
+func shrink(src image.Image, width, height int) *image.RGBA {
+	s := toRGBA(src)
+	sw, sh := s.Bounds().Dx(), s.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, maxInt((y+1)*sh/height, y*sh/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, maxInt((x+1)*sw/width, x*sw/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := s.PixOffset(sx, sy)
+					for c := 0; c < 4; c++ {
+						sum[c] += int(s.Pix[i+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// enlarge scales the image up to width x height with bilinear
+// interpolation, which blurs it.
+func enlarge(src *image.RGBA, width, height int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		fy := (float64(y)+0.5)*float64(sh)/float64(height) - 0.5
+		y0, wy := split(fy, sh)
+		for x := 0; x < width; x++ {
+			fx := (float64(x)+0.5)*float64(sw)/float64(width) - 0.5
+			x0, wx := split(fx, sw)
+			x1, y1 := minInt(x0+1, sw-1), minInt(y0+1, sh-1)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				top := float64(src.Pix[src.PixOffset(x0, y0)+c])*(1-wx) + float64(src.Pix[src.PixOffset(x1, y0)+c])*wx
+				bottom := float64(src.Pix[src.PixOffset(x0, y1)+c])*(1-wx) + float64(src.Pix[src.PixOffset(x1, y1)+c])*wx
+				dst.Pix[i+c] = uint8(top*(1-wy) + bottom*wy + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// split gets the index of the pixel before f, and how far f is
+// towards the next pixel.
+func split(f float64, size int) (int, float64) {
+	if f <= 0 {
+		return 0, 0
+	}
+	i := int(f)
+	if i >= size-1 {
+		return size - 1, 0
+	}
+	return i, f - float64(i)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+package nudebox_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/nudebox"
+	"github.com/matryer/is"
+)
+
+// checkerboard makes a PNG of black and white squares.
+func checkerboard(is *is.I, width, height, square int) []byte {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	is.NoErr(png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// sharpness gets the largest difference between horizontally
+// adjacent pixels.
+func sharpness(img image.Image) uint32 {
+	var max uint32
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X + 1; x < b.Max.X; x++ {
+			r1, _, _, _ := img.At(x-1, y).RGBA()
+			r2, _, _, _ := img.At(x, y).RGBA()
+			diff := r1 - r2
+			if r2 > r1 {
+				diff = r2 - r1
+			}
+			if diff > max {
+				max = diff
+			}
+		}
+	}
+	return max >> 8
+}
+
+func TestPreview(t *testing.T) {
+	is := is.New(t)
+	nude := 0.1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/nudebox/check")
+		fmt.Fprintf(w, `{"success": true, "nude": %v}`, nude)
+	}))
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	data := checkerboard(is, 800, 400, 40)
+
+	preview, err := nb.Preview(bytes.NewReader(data), nil)
+	is.NoErr(err)
+	is.Equal(preview.Nude, 0.1)
+	is.Equal(preview.Blurred, false)
+	is.Equal(preview.Image.Bounds(), image.Rect(0, 0, 256, 128))
+	is.True(sharpness(preview.Image) > 200)
+	img, err := jpeg.Decode(bytes.NewReader(preview.JPEG))
+	is.NoErr(err)
+	is.Equal(img.Bounds(), image.Rect(0, 0, 256, 128))
+
+	nude = 0.9
+	preview, err = nb.Preview(bytes.NewReader(data), &nudebox.PreviewOptions{
+		Threshold: 0.8,
+		MaxSize:   128,
+	})
+	is.NoErr(err)
+	is.Equal(preview.Nude, 0.9)
+	is.Equal(preview.Blurred, true)
+	is.Equal(preview.Image.Bounds(), image.Rect(0, 0, 128, 64))
+	is.True(sharpness(preview.Image) < 40)
+}
+
+func TestPreviewSmallImage(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "nude": 0.6}`)
+	}))
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	preview, err := nb.Preview(bytes.NewReader(checkerboard(is, 100, 50, 5)), nil)
+	is.NoErr(err)
+	is.Equal(preview.Blurred, true)
+	is.Equal(preview.Image.Bounds(), image.Rect(0, 0, 100, 50)) // small images are not enlarged
+}
+
+func TestPreviewNotImage(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Fail() // should not check images that can not be decoded
+	}))
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	_, err := nb.Preview(bytes.NewReader([]byte("not an image")), nil)
+	is.Equal(err.Error(), "decode image: image: unknown format")
+}
+
+func TestPreviewLargeBlurSize(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "nude": 0.9}`)
+	}))
+	defer srv.Close()
+	nb := nudebox.New(srv.URL)
+	preview, err := nb.Preview(bytes.NewReader(checkerboard(is, 100, 100, 1)), &nudebox.PreviewOptions{
+		BlurSize: 300,
+	})
+	is.NoErr(err)
+	is.Equal(preview.Blurred, true)
+	is.Equal(preview.Image.Bounds(), image.Rect(0, 0, 100, 100))
+	is.True(sharpness(preview.Image) < 40) // neighbouring pixels must not stay black and white
+}
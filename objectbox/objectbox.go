@@ -11,11 +11,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Object is an object found by a detector.
 type Object struct {
-	Rect  Rect    `json:"rect"`
+	// Rect is where the object is in the image.
+	Rect Rect `json:"rect"`
+	// Score is the confidence of the detection between 0 and 1.
 	Score float64 `json:"score"`
 }
 
+// Rect represents the coordinates of an object within an image.
 type Rect struct {
 	Top    int `json:"top"`
 	Left   int `json:"left"`
@@ -72,8 +76,64 @@ type CheckResponse struct {
 	Detectors []CheckDetectorResponse `json:"detectors"`
 }
 
+// CheckDetectorResponse is the objects found by a single detector.
 type CheckDetectorResponse struct {
-	ID      string   `json:"id"`
-	Name    string   `json:"name"`
+	// ID is the unique identifier of the detector.
+	ID string `json:"id"`
+	// Name is the name of the detector.
+	Name string `json:"name"`
+	// Objects are the objects found by the detector.
 	Objects []Object `json:"objects"`
 }
+
+// Detector gets the results of the detector with the specified ID.
+func (r CheckResponse) Detector(id string) (CheckDetectorResponse, bool) {
+	for _, detector := range r.Detectors {
+		if detector.ID == id {
+			return detector, true
+		}
+	}
+	return CheckDetectorResponse{}, false
+}
+
+// OnlyDetectors gets a copy of the response that only includes the
+// detectors with the specified IDs.
+func (r CheckResponse) OnlyDetectors(ids ...string) CheckResponse {
+	keep := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	filtered := CheckResponse{Detectors: []CheckDetectorResponse{}}
+	for _, detector := range r.Detectors {
+		if keep[detector.ID] {
+			filtered.Detectors = append(filtered.Detectors, detector)
+		}
+	}
+	return filtered
+}
+
+// MinScore gets a copy of the response that only includes objects
+// with at least the specified score.
+func (r CheckResponse) MinScore(score float64) CheckResponse {
+	filtered := CheckResponse{Detectors: make([]CheckDetectorResponse, len(r.Detectors))}
+	for i, detector := range r.Detectors {
+		objects := []Object{}
+		for _, object := range detector.Objects {
+			if object.Score >= score {
+				objects = append(objects, object)
+			}
+		}
+		detector.Objects = objects
+		filtered.Detectors[i] = detector
+	}
+	return filtered
+}
+
+// Count gets the total number of objects found by all detectors.
+func (r CheckResponse) Count() int {
+	var count int
+	for _, detector := range r.Detectors {
+		count += len(detector.Objects)
+	}
+	return count
+}
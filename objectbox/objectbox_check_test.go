@@ -0,0 +1,113 @@
+package objectbox_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+const checkResponse = `{
+	"success": true,
+	"detectors": [
+		{
+			"id": "det1",
+			"name": "cars",
+			"objects": [
+				{"rect": {"top": 1, "left": 2, "width": 3, "height": 4}, "score": 0.9},
+				{"rect": {"top": 5, "left": 6, "width": 7, "height": 8}, "score": 0.3}
+			]
+		}
+	]
+}`
+
+func assertCheckResponse(is *is.I, res objectbox.CheckResponse) {
+	is.Equal(len(res.Detectors), 1)
+	is.Equal(res.Detectors[0].ID, "det1")
+	is.Equal(res.Detectors[0].Name, "cars")
+	is.Equal(len(res.Detectors[0].Objects), 2)
+	is.Equal(res.Detectors[0].Objects[0].Rect, objectbox.Rect{Top: 1, Left: 2, Width: 3, Height: 4})
+	is.Equal(res.Detectors[0].Objects[0].Score, 0.9)
+	is.Equal(res.Detectors[0].Objects[1].Score, 0.3)
+}
+
+func TestCheckImage(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/check")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		is.Equal(string(b), `(pretend this is image data)`)
+		io.WriteString(w, checkResponse)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	res, err := ob.Check(strings.NewReader(`(pretend this is image data)`))
+	is.NoErr(err)
+	assertCheckResponse(is, res)
+}
+
+func TestCheckURL(t *testing.T) {
+	is := is.New(t)
+	imageURL, err := url.Parse("https://test.machinebox.io/image1.png")
+	is.NoErr(err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/check")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		is.Equal(r.FormValue("url"), imageURL.String())
+		io.WriteString(w, checkResponse)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	res, err := ob.CheckURL(imageURL)
+	is.NoErr(err)
+	assertCheckResponse(is, res)
+
+	_, err = ob.CheckURL(&url.URL{Path: "image1.png"})
+	is.Equal(err.Error(), "url must be absolute")
+}
+
+func TestCheckBase64(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/check")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		is.Equal(r.FormValue("base64"), "base64Str")
+		io.WriteString(w, checkResponse)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	res, err := ob.CheckBase64("base64Str")
+	is.NoErr(err)
+	assertCheckResponse(is, res)
+}
+
+func TestCheckError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"success": false,
+			"error": "something went wrong"
+		}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	_, err := ob.Check(strings.NewReader(`(pretend this is image data)`))
+	is.True(err != nil)
+	is.Equal(err.Error(), "objectbox: something went wrong")
+	_, err = ob.CheckBase64("base64Str")
+	is.Equal(err.Error(), "objectbox: something went wrong")
+}
@@ -0,0 +1,72 @@
+package objectbox
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/machinebox/sdk-go/internal/mbhttp"
+	"github.com/pkg/errors"
+)
+
+// Detector describes a detector configured in objectbox.
+type Detector struct {
+	// ID is the unique identifier of the detector.
+	ID string `json:"id"`
+	// Name is the name of the detector.
+	Name string `json:"name"`
+	// Type is the kind of detector.
+	Type string `json:"type"`
+	// Labels are the kinds of object the detector finds.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Detectors gets the detectors configured in objectbox.
+func (c *Client) Detectors() ([]Detector, error) {
+	u, err := url.Parse(c.addr + "/objectbox/detectors")
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, errors.New("box address must be absolute")
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	var response struct {
+		Detectors []Detector `json:"detectors"`
+	}
+	_, err = mbhttp.New("objectbox", c.HTTPClient).DoUnmarshal(req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Detectors, nil
+}
+
+// Detector gets the detector with the specified ID.
+func (c *Client) Detector(id string) (*Detector, error) {
+	if id == "" {
+		return nil, errors.New("id can not be empty")
+	}
+	u, err := url.Parse(c.addr + "/objectbox/detectors/" + url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, errors.New("box address must be absolute")
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	var response struct {
+		Detector Detector `json:"detector"`
+	}
+	_, err = mbhttp.New("objectbox", c.HTTPClient).DoUnmarshal(req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Detector, nil
+}
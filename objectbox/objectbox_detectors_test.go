@@ -0,0 +1,74 @@
+package objectbox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func TestDetectors(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/objectbox/detectors")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		io.WriteString(w, `{
+			"success": true,
+			"detectors": [
+				{"id": "det1", "name": "cars", "type": "tensorflow", "labels": ["car", "truck"]},
+				{"id": "det2", "name": "people", "type": "tensorflow"}
+			]
+		}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	detectors, err := ob.Detectors()
+	is.NoErr(err)
+	is.Equal(len(detectors), 2)
+	is.Equal(detectors[0], objectbox.Detector{
+		ID:     "det1",
+		Name:   "cars",
+		Type:   "tensorflow",
+		Labels: []string{"car", "truck"},
+	})
+	is.Equal(detectors[1].ID, "det2")
+	is.Equal(detectors[1].Name, "people")
+}
+
+func TestDetector(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/objectbox/detectors/det1")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		io.WriteString(w, `{
+			"success": true,
+			"detector": {"id": "det1", "name": "cars", "type": "tensorflow", "labels": ["car", "truck"]}
+		}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	detector, err := ob.Detector("det1")
+	is.NoErr(err)
+	is.Equal(detector.ID, "det1")
+	is.Equal(detector.Labels, []string{"car", "truck"})
+
+	_, err = ob.Detector("")
+	is.Equal(err.Error(), "id can not be empty")
+}
+
+func TestDetectorsError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success": false, "error": "something went wrong"}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	_, err := ob.Detectors()
+	is.True(err != nil)
+	is.Equal(err.Error(), "objectbox: something went wrong")
+}
@@ -12,6 +12,27 @@ import (
 	"github.com/machinebox/sdk-go/internal/mbhttp"
 )
 
+// OpenState opens the state file for reading.
+// Clients must call Close.
+func (c *Client) OpenState() (io.ReadCloser, error) {
+	u, err := url.Parse(c.addr + "/objectbox/state")
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, errors.New("box address must be absolute")
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // PostState uploads new state data.
 func (c *Client) PostState(r io.Reader) error {
 	var buf bytes.Buffer
@@ -0,0 +1,107 @@
+package objectbox_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func TestOpenState(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/objectbox/state")
+		io.WriteString(w, `(pretend this is the state file)`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	f, err := ob.OpenState()
+	is.NoErr(err)
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	is.NoErr(err)
+	is.Equal(string(b), `(pretend this is the state file)`)
+}
+
+func TestPostState(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/state")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		is.Equal(string(b), `(pretend this is the state file)`)
+		io.WriteString(w, `{"success":true}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	r := strings.NewReader(`(pretend this is the state file)`)
+	err := ob.PostState(r)
+	is.NoErr(err)
+}
+
+func TestPostStateError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/state")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		b, err := ioutil.ReadAll(f)
+		is.NoErr(err)
+		is.Equal(string(b), `(pretend this is the state file)`)
+		io.WriteString(w, `{"success":false,"error":"something went wrong"}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	r := strings.NewReader(`(pretend this is the state file)`)
+	err := ob.PostState(r)
+	is.True(err != nil)
+	is.Equal(err.Error(), "objectbox: something went wrong")
+}
+
+func TestPostStateURL(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/state")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		is.Equal(r.FormValue("url"), "https://test.machinebox.io/test.objectbox")
+		io.WriteString(w, `{"success":true}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	u, err := url.Parse("https://test.machinebox.io/test.objectbox")
+	is.NoErr(err)
+	err = ob.PostStateURL(u)
+	is.NoErr(err)
+}
+
+func TestPostStateURLError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.Path, "/objectbox/state")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		is.Equal(r.FormValue("url"), "https://test.machinebox.io/test.objectbox")
+		io.WriteString(w, `{"success":false,"error":"something went wrong"}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	u, err := url.Parse("https://test.machinebox.io/test.objectbox")
+	is.NoErr(err)
+	err = ob.PostStateURL(u)
+	is.True(err != nil)
+	is.Equal(err.Error(), "objectbox: something went wrong")
+}
@@ -0,0 +1,90 @@
+package objectbox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func TestInfo(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/info")
+		is.Equal(r.Header.Get("Accept"), "application/json; charset=utf-8")
+		io.WriteString(w, `{
+			"success": true,
+			"name": "objectbox",
+			"version": 1,
+			"build": "abcdefg",
+			"status": "ready"
+		}`)
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	info, err := ob.Info()
+	is.NoErr(err)
+	is.Equal(info.Name, "objectbox")
+	is.Equal(info.Version, 1)
+	is.Equal(info.Build, "abcdefg")
+	is.Equal(info.Status, "ready")
+}
+
+func TestInfoRelativeAddress(t *testing.T) {
+	is := is.New(t)
+	ob := objectbox.New("objectbox.local")
+	_, err := ob.Info()
+	is.Equal(err.Error(), "box address must be absolute")
+}
+
+var testResponse = objectbox.CheckResponse{
+	Detectors: []objectbox.CheckDetectorResponse{
+		{
+			ID:   "det1",
+			Name: "cars",
+			Objects: []objectbox.Object{
+				{Rect: objectbox.Rect{Top: 1, Left: 2, Width: 3, Height: 4}, Score: 0.9},
+				{Rect: objectbox.Rect{Top: 5, Left: 6, Width: 7, Height: 8}, Score: 0.3},
+			},
+		},
+		{
+			ID:   "det2",
+			Name: "people",
+			Objects: []objectbox.Object{
+				{Rect: objectbox.Rect{Top: 10, Left: 20, Width: 30, Height: 40}, Score: 0.6},
+			},
+		},
+	},
+}
+
+func TestCheckResponseDetector(t *testing.T) {
+	is := is.New(t)
+	detector, ok := testResponse.Detector("det2")
+	is.True(ok)
+	is.Equal(detector.Name, "people")
+	_, ok = testResponse.Detector("det3")
+	is.Equal(ok, false)
+}
+
+func TestCheckResponseOnlyDetectors(t *testing.T) {
+	is := is.New(t)
+	res := testResponse.OnlyDetectors("det2", "det3")
+	is.Equal(len(res.Detectors), 1)
+	is.Equal(res.Detectors[0].ID, "det2")
+	is.Equal(len(testResponse.Detectors), 2) // original is unchanged
+}
+
+func TestCheckResponseMinScore(t *testing.T) {
+	is := is.New(t)
+	res := testResponse.MinScore(0.6)
+	is.Equal(len(res.Detectors), 2)
+	is.Equal(len(res.Detectors[0].Objects), 1)
+	is.Equal(res.Detectors[0].Objects[0].Score, 0.9)
+	is.Equal(len(res.Detectors[1].Objects), 1)
+	is.Equal(res.Count(), 2)
+	is.Equal(testResponse.Count(), 3) // original is unchanged
+}
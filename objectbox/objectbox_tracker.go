@@ -0,0 +1,385 @@
+package objectbox
+
+import (
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrackEventType is the kind of TrackEvent.
+type TrackEventType string
+
+const (
+	// TrackStart indicates that a new object is being tracked.
+	TrackStart TrackEventType = "start"
+	// TrackUpdate indicates that a tracked object was seen again.
+	TrackUpdate TrackEventType = "update"
+	// TrackEnd indicates that a tracked object has gone.
+	TrackEnd TrackEventType = "end"
+)
+
+// TrackEvent describes a change to a tracked object.
+type TrackEvent struct {
+	// Type is the kind of event.
+	Type TrackEventType `json:"type"`
+	// TrackID uniquely identifies the object within the tracker.
+	TrackID int `json:"track_id"`
+	// Detector is the ID of the detector that found the object.
+	Detector string `json:"detector"`
+	// Time is when the object was last seen.
+	Time time.Time `json:"time"`
+	// Object is the most recent sighting of the object.
+	Object Object `json:"object"`
+	// Trajectory is where the object has been seen, oldest first.
+	// It is only set for TrackStart and TrackEnd, and holds at most
+	// TrackerOptions.MaxTrajectory of the most recent points.
+	Trajectory []TrackPoint `json:"trajectory,omitempty"`
+}
+
+// TrackPoint is a sighting of a tracked object.
+type TrackPoint struct {
+	Time  time.Time `json:"time"`
+	Rect  Rect      `json:"rect"`
+	Score float64   `json:"score"`
+}
+
+// TrackAssociation is how objects are associated with tracks.
+type TrackAssociation int
+
+const (
+	// TrackIoU associates objects with the track whose last rect
+	// overlaps the most, by intersection over union.
+	TrackIoU TrackAssociation = iota
+	// TrackCentroid associates objects with the track whose last
+	// rect has the nearest center.
+	TrackCentroid
+)
+
+// TrackerOptions control the behaviour of a Tracker.
+type TrackerOptions struct {
+	// Association is how objects are associated with tracks.
+	// Defaults to TrackIoU.
+	Association TrackAssociation
+	// MinOverlap is the minimum intersection over union for
+	// TrackIoU. Defaults to 0.3.
+	MinOverlap float64
+	// MaxDistance is the maximum distance in pixels between centers
+	// for TrackCentroid. Defaults to 50.
+	MaxDistance float64
+	// MaxAge is the number of consecutive frames an object can be
+	// missing before its track ends. Defaults to 5.
+	MaxAge int
+	// MinHits is the number of frames an object must be seen in
+	// before its track starts. Defaults to 2.
+	MinHits int
+	// MinScore is the minimum score of objects that are tracked.
+	MinScore float64
+	// MaxTrajectory is the maximum number of points kept for each
+	// track; older points are dropped. Defaults to 100.
+	MaxTrajectory int
+	// Buffer is the size of the events channel. Defaults to 100.
+	// Events that do not fit are held by the tracker until they
+	// are read.
+	Buffer int
+}
+
+// Tracker assigns persistent IDs to objects across a sequence of
+// frames, and emits events as they come and go.
+// Objects are only associated with tracks from the same detector.
+type Tracker struct {
+	client  *Client
+	options TrackerOptions
+	events  chan TrackEvent
+
+	lock    sync.Mutex
+	ready   *sync.Cond // signalled when pending or closed changes
+	nextID  int
+	started int
+	tracks  []*objectTrack
+	pending []TrackEvent
+	closed  bool
+}
+
+type objectTrack struct {
+	id         int
+	detector   string
+	object     Object
+	trajectory []TrackPoint
+	hits       int
+	missed     int
+	started    bool
+}
+
+// NewTracker makes a new Tracker that uses the client to check frames.
+// Callers must read from Events, and call Close when finished.
+func NewTracker(client *Client, options *TrackerOptions) *Tracker {
+	var o TrackerOptions
+	if options != nil {
+		o = *options
+	}
+	if o.MinOverlap <= 0 {
+		o.MinOverlap = 0.3
+	}
+	if o.MaxDistance <= 0 {
+		o.MaxDistance = 50
+	}
+	if o.MaxAge < 1 {
+		o.MaxAge = 5
+	}
+	if o.MinHits < 1 {
+		o.MinHits = 2
+	}
+	if o.MaxTrajectory < 1 {
+		o.MaxTrajectory = 100
+	}
+	if o.Buffer < 1 {
+		o.Buffer = 100
+	}
+	t := &Tracker{
+		client:  client,
+		options: o,
+		events:  make(chan TrackEvent, o.Buffer),
+	}
+	t.ready = sync.NewCond(&t.lock)
+	go t.send()
+	return t
+}
+
+// send delivers pending events to the Events channel, and closes
+// it once the tracker is closed and every event has been sent.
+// Events are sent without holding the lock so that a slow reader
+// never blocks Track or Close.
+func (t *Tracker) send() {
+	for {
+		t.lock.Lock()
+		for len(t.pending) == 0 && !t.closed {
+			t.ready.Wait()
+		}
+		if len(t.pending) == 0 {
+			t.lock.Unlock()
+			close(t.events)
+			return
+		}
+		event := t.pending[0]
+		t.pending[0] = TrackEvent{}
+		t.pending = t.pending[1:]
+		t.lock.Unlock()
+		t.events <- event
+	}
+}
+
+// Events gets the channel on which events are sent.
+// The channel is closed by Close.
+func (t *Tracker) Events() <-chan TrackEvent {
+	return t.events
+}
+
+// Count gets the number of tracks that have started, which is the
+// number of unique objects seen.
+func (t *Tracker) Count() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.started
+}
+
+// Check checks the frame in the io.Reader for objects and tracks them.
+// Frames must be provided in time order.
+func (t *Tracker) Check(frame io.Reader, timestamp time.Time) error {
+	response, err := t.client.Check(frame)
+	if err != nil {
+		return err
+	}
+	t.Track(response, timestamp)
+	return nil
+}
+
+// trackCandidate is a possible association of an object with a track.
+type trackCandidate struct {
+	detector int
+	object   int
+	track    *objectTrack
+	cost     float64
+}
+
+// Track tracks objects that have already been checked.
+// Frames must be provided in time order.
+func (t *Tracker) Track(response CheckResponse, timestamp time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	// find every possible association, and take the best ones first
+	var candidates []trackCandidate
+	for d, detector := range response.Detectors {
+		for o, object := range detector.Objects {
+			if object.Score < t.options.MinScore {
+				continue
+			}
+			for _, track := range t.tracks {
+				if track.detector != detector.ID {
+					continue
+				}
+				if cost, ok := t.cost(object.Rect, track.object.Rect); ok {
+					candidates = append(candidates, trackCandidate{
+						detector: d,
+						object:   o,
+						track:    track,
+						cost:     cost,
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].cost < candidates[j].cost
+	})
+	type objectKey struct{ detector, object int }
+	assigned := make(map[objectKey]*objectTrack)
+	seen := make(map[*objectTrack]bool)
+	for _, candidate := range candidates {
+		key := objectKey{candidate.detector, candidate.object}
+		if assigned[key] != nil || seen[candidate.track] {
+			continue
+		}
+		assigned[key] = candidate.track
+		seen[candidate.track] = true
+	}
+	for d, detector := range response.Detectors {
+		for o, object := range detector.Objects {
+			if object.Score < t.options.MinScore {
+				continue
+			}
+			track := assigned[objectKey{d, o}]
+			if track == nil {
+				t.nextID++
+				track = &objectTrack{id: t.nextID, detector: detector.ID}
+				t.tracks = append(t.tracks, track)
+				seen[track] = true
+			}
+			track.object = object
+			track.hits++
+			track.missed = 0
+			if len(track.trajectory) >= t.options.MaxTrajectory {
+				n := copy(track.trajectory, track.trajectory[len(track.trajectory)-t.options.MaxTrajectory+1:])
+				track.trajectory = track.trajectory[:n]
+			}
+			track.trajectory = append(track.trajectory, TrackPoint{
+				Time:  timestamp,
+				Rect:  object.Rect,
+				Score: object.Score,
+			})
+			switch {
+			case track.started:
+				t.emit(TrackUpdate, track, false)
+			case track.hits >= t.options.MinHits:
+				track.started = true
+				t.started++
+				t.emit(TrackStart, track, true)
+			}
+		}
+	}
+	tracks := t.tracks[:0]
+	for _, track := range t.tracks {
+		if !seen[track] {
+			track.missed++
+		}
+		if track.missed <= t.options.MaxAge {
+			tracks = append(tracks, track)
+			continue
+		}
+		t.end(track)
+	}
+	t.tracks = tracks
+}
+
+// cost gets how well the rect matches the track's last rect, lower
+// is better, and whether they can be associated at all.
+func (t *Tracker) cost(rect, last Rect) (float64, bool) {
+	switch t.options.Association {
+	case TrackCentroid:
+		x1, y1 := rect.center()
+		x2, y2 := last.center()
+		distance := math.Hypot(x1-x2, y1-y2)
+		return distance, distance <= t.options.MaxDistance
+	default:
+		overlap := rect.iou(last)
+		return -overlap, overlap >= t.options.MinOverlap
+	}
+}
+
+// emit queues an event for the track.
+// Callers must hold the lock.
+func (t *Tracker) emit(eventType TrackEventType, track *objectTrack, trajectory bool) {
+	event := TrackEvent{
+		Type:     eventType,
+		TrackID:  track.id,
+		Detector: track.detector,
+		Time:     track.trajectory[len(track.trajectory)-1].Time,
+		Object:   track.object,
+	}
+	if trajectory {
+		event.Trajectory = make([]TrackPoint, len(track.trajectory))
+		copy(event.Trajectory, track.trajectory)
+	}
+	t.pending = append(t.pending, event)
+	t.ready.Signal()
+}
+
+// end queues the TrackEnd event for the track, if it started.
+// Callers must hold the lock.
+func (t *Tracker) end(track *objectTrack) {
+	if !track.started {
+		// noise; never announced
+		return
+	}
+	t.emit(TrackEnd, track, true)
+}
+
+// Close sends TrackEnd events for every object still being tracked,
+// and closes the Events channel once every event has been read.
+func (t *Tracker) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, track := range t.tracks {
+		t.end(track)
+	}
+	t.tracks = nil
+	t.ready.Signal()
+}
+
+func (r Rect) center() (float64, float64) {
+	return float64(r.Left) + float64(r.Width)/2, float64(r.Top) + float64(r.Height)/2
+}
+
+// iou gets the intersection over union of two rects.
+func (r Rect) iou(other Rect) float64 {
+	left, top := maxInt(r.Left, other.Left), maxInt(r.Top, other.Top)
+	right, bottom := minInt(r.Left+r.Width, other.Left+other.Width), minInt(r.Top+r.Height, other.Top+other.Height)
+	if right <= left || bottom <= top {
+		return 0
+	}
+	intersection := (right - left) * (bottom - top)
+	union := r.Width*r.Height + other.Width*other.Height - intersection
+	return float64(intersection) / float64(union)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,175 @@
+package objectbox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+func trackerResponse(cars ...objectbox.Rect) objectbox.CheckResponse {
+	response := objectbox.CheckResponse{
+		Detectors: []objectbox.CheckDetectorResponse{
+			{ID: "cars"},
+		},
+	}
+	for _, rect := range cars {
+		response.Detectors[0].Objects = append(response.Detectors[0].Objects, objectbox.Object{Rect: rect, Score: 0.9})
+	}
+	return response
+}
+
+func TestTracker(t *testing.T) {
+	is := is.New(t)
+	tracker := objectbox.NewTracker(nil, &objectbox.TrackerOptions{
+		MaxAge: 1,
+	})
+	start := time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(frame int) time.Time {
+		return start.Add(time.Duration(frame) * time.Second)
+	}
+	car := func(left, top int) objectbox.Rect {
+		return objectbox.Rect{Left: left, Top: top, Width: 100, Height: 100}
+	}
+	flicker := objectbox.Rect{Left: 500, Top: 500, Width: 20, Height: 20}
+
+	tracker.Track(trackerResponse(car(0, 0), flicker), at(0))
+	frame := trackerResponse(car(20, 0), car(0, 300))
+	// a person in the same place as the car is not the car
+	frame.Detectors = append(frame.Detectors, objectbox.CheckDetectorResponse{
+		ID:      "people",
+		Objects: []objectbox.Object{{Rect: car(0, 0), Score: 0.9}},
+	})
+	tracker.Track(frame, at(1))
+	tracker.Track(trackerResponse(car(40, 0), car(10, 300)), at(2))
+	tracker.Track(trackerResponse(), at(3))
+	tracker.Track(trackerResponse(), at(4))
+	is.Equal(tracker.Count(), 2)
+	tracker.Close()
+
+	var events []objectbox.TrackEvent
+	for event := range tracker.Events() {
+		events = append(events, event)
+	}
+	is.Equal(len(events), 5)
+
+	is.Equal(events[0].Type, objectbox.TrackStart)
+	is.Equal(events[0].Detector, "cars")
+	is.Equal(events[0].Time, at(1))
+	is.Equal(len(events[0].Trajectory), 2)
+	is.Equal(events[0].Trajectory[0].Rect, car(0, 0))
+	is.Equal(events[0].Trajectory[1].Rect, car(20, 0))
+
+	is.Equal(events[1].Type, objectbox.TrackUpdate)
+	is.Equal(events[1].TrackID, events[0].TrackID)
+	is.Equal(events[1].Object.Rect, car(40, 0))
+	is.Equal(events[1].Trajectory, nil)
+
+	is.Equal(events[2].Type, objectbox.TrackStart)
+	is.True(events[2].TrackID != events[0].TrackID)
+	is.Equal(events[2].Object.Rect, car(10, 300))
+
+	is.Equal(events[3].Type, objectbox.TrackEnd)
+	is.Equal(events[3].TrackID, events[0].TrackID)
+	is.Equal(events[3].Time, at(2))
+	is.Equal(len(events[3].Trajectory), 3)
+	is.Equal(events[4].Type, objectbox.TrackEnd)
+	is.Equal(events[4].TrackID, events[2].TrackID)
+}
+
+func TestTrackerCentroid(t *testing.T) {
+	is := is.New(t)
+	tracker := objectbox.NewTracker(nil, &objectbox.TrackerOptions{
+		Association: objectbox.TrackCentroid,
+		MaxDistance: 60,
+	})
+	now := time.Now()
+	// moves too fast for the rects to overlap
+	for i := 0; i < 3; i++ {
+		tracker.Track(trackerResponse(objectbox.Rect{Left: i * 40, Top: 0, Width: 20, Height: 20}), now)
+	}
+	tracker.Track(trackerResponse(objectbox.Rect{Left: 400, Top: 0, Width: 20, Height: 20}), now)
+	tracker.Close()
+	var events []objectbox.TrackEvent
+	for event := range tracker.Events() {
+		events = append(events, event)
+	}
+	is.Equal(len(events), 3)
+	is.Equal(events[0].Type, objectbox.TrackStart)
+	is.Equal(events[1].Type, objectbox.TrackUpdate)
+	is.Equal(events[2].Type, objectbox.TrackEnd)
+	is.Equal(len(events[2].Trajectory), 3)
+	is.Equal(events[2].Object.Rect.Left, 80)
+}
+
+func TestTrackerCheck(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/objectbox/check")
+		io.WriteString(w, `{
+			"success": true,
+			"detectors": [
+				{
+					"id": "cars",
+					"objects": [
+						{ "rect": { "top": 0, "left": 0, "width": 120, "height": 120 }, "score": 0.8 }
+					]
+				}
+			]
+		}`)
+	}))
+	defer srv.Close()
+	tracker := objectbox.NewTracker(objectbox.New(srv.URL), &objectbox.TrackerOptions{
+		MinHits: 1,
+	})
+	now := time.Now()
+	is.NoErr(tracker.Check(strings.NewReader(`(pretend this is image data)`), now))
+	tracker.Close()
+	event := <-tracker.Events()
+	is.Equal(event.Type, objectbox.TrackStart)
+	is.Equal(event.Detector, "cars")
+	is.Equal(event.Object.Rect.Width, 120)
+	event = <-tracker.Events()
+	is.Equal(event.Type, objectbox.TrackEnd)
+	_, ok := <-tracker.Events()
+	is.Equal(ok, false)
+}
+
+func TestTrackerUnreadEvents(t *testing.T) {
+	is := is.New(t)
+	tracker := objectbox.NewTracker(nil, &objectbox.TrackerOptions{
+		MinHits:       1,
+		MaxTrajectory: 10,
+		Buffer:        1,
+	})
+	start := time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 300; i++ {
+			rect := objectbox.Rect{Left: i, Top: 0, Width: 100, Height: 100}
+			tracker.Track(trackerResponse(rect), start.Add(time.Duration(i)*time.Second))
+		}
+		tracker.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Track or Close blocked on unread events")
+	}
+	var events []objectbox.TrackEvent
+	for event := range tracker.Events() {
+		events = append(events, event)
+	}
+	is.Equal(len(events), 301)
+	end := events[len(events)-1]
+	is.Equal(end.Type, objectbox.TrackEnd)
+	is.Equal(len(end.Trajectory), 10)
+	is.Equal(end.Trajectory[0].Rect.Left, 290)
+	is.Equal(end.Trajectory[9].Rect.Left, 299)
+}
@@ -0,0 +1,304 @@
+package objectbox
+
+import (
+	"context"
+	"math"
+)
+
+// Point is a position in an image, in pixels.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Zone is a named polygon in an image.
+type Zone struct {
+	// Name is the unique name of the zone.
+	Name string `json:"name"`
+	// Polygon are the vertices of the zone, in order. Zones with
+	// fewer than three points contain no objects.
+	Polygon []Point `json:"polygon"`
+}
+
+// contains checks whether the point is inside the zone.
+func (z Zone) contains(p Point) bool {
+	inside := false
+	n := len(z.Polygon)
+	if n < 3 {
+		return false
+	}
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// overlap gets the fraction of the rect that is inside the zone.
+func (z Zone) overlap(r Rect) float64 {
+	area := float64(r.Width * r.Height)
+	if area == 0 || len(z.Polygon) < 3 {
+		return 0
+	}
+	return polygonArea(clip(z.Polygon, r)) / area
+}
+
+// clip clips the polygon to the rect using the Sutherland-Hodgman
+// algorithm.
+func clip(polygon []Point, r Rect) []Point {
+	left, top := float64(r.Left), float64(r.Top)
+	right, bottom := left+float64(r.Width), top+float64(r.Height)
+	edges := []struct {
+		inside    func(p Point) bool
+		intersect func(a, b Point) Point
+	}{
+		{
+			inside: func(p Point) bool { return p.X >= left },
+			intersect: func(a, b Point) Point {
+				return Point{X: left, Y: a.Y + (b.Y-a.Y)*(left-a.X)/(b.X-a.X)}
+			},
+		},
+		{
+			inside: func(p Point) bool { return p.X <= right },
+			intersect: func(a, b Point) Point {
+				return Point{X: right, Y: a.Y + (b.Y-a.Y)*(right-a.X)/(b.X-a.X)}
+			},
+		},
+		{
+			inside: func(p Point) bool { return p.Y >= top },
+			intersect: func(a, b Point) Point {
+				return Point{X: a.X + (b.X-a.X)*(top-a.Y)/(b.Y-a.Y), Y: top}
+			},
+		},
+		{
+			inside: func(p Point) bool { return p.Y <= bottom },
+			intersect: func(a, b Point) Point {
+				return Point{X: a.X + (b.X-a.X)*(bottom-a.Y)/(b.Y-a.Y), Y: bottom}
+			},
+		},
+	}
+	output := polygon
+	for _, edge := range edges {
+		input := output
+		output = nil
+		for i, current := range input {
+			previous := input[(i+len(input)-1)%len(input)]
+			switch {
+			case edge.inside(current):
+				if !edge.inside(previous) {
+					output = append(output, edge.intersect(previous, current))
+				}
+				output = append(output, current)
+			case edge.inside(previous):
+				output = append(output, edge.intersect(previous, current))
+			}
+		}
+		if len(output) == 0 {
+			return nil
+		}
+	}
+	return output
+}
+
+// polygonArea gets the area of the polygon using the shoelace formula.
+func polygonArea(polygon []Point) float64 {
+	var sum float64
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		sum += a.X*b.Y - b.X*a.Y
+	}
+	return math.Abs(sum) / 2
+}
+
+// ZoneMode is how objects are assigned to zones.
+type ZoneMode int
+
+const (
+	// ZoneCentroid assigns an object to the zones that contain
+	// the center of its rect.
+	ZoneCentroid ZoneMode = iota
+	// ZoneOverlap assigns an object to the zones that contain at
+	// least ZoneOptions.MinOverlap of its rect.
+	ZoneOverlap
+)
+
+// ZoneOptions control how objects are counted in zones.
+type ZoneOptions struct {
+	// Mode is how objects are assigned to zones.
+	// Defaults to ZoneCentroid.
+	Mode ZoneMode
+	// MinOverlap is the fraction of an object's rect that must be
+	// inside a zone for ZoneOverlap. Defaults to 0.5.
+	MinOverlap float64
+	// MinScore is the minimum score of objects that are counted.
+	MinScore float64
+}
+
+// ZoneCount is the number of objects in a zone.
+type ZoneCount struct {
+	// Zone is the name of the zone.
+	Zone string `json:"zone"`
+	// Total is the number of objects in the zone.
+	Total int `json:"total"`
+	// Detectors are the number of objects in the zone found by each
+	// detector, by detector ID. Each detector finds a kind of object,
+	// such as people or vehicles.
+	Detectors map[string]int `json:"detectors"`
+}
+
+// CountZones counts the objects in each zone. An object may be
+// counted in more than one zone if zones overlap.
+// Counts are in the same order as zones.
+func CountZones(zones []Zone, response CheckResponse, options *ZoneOptions) []ZoneCount {
+	if options == nil {
+		options = &ZoneOptions{}
+	}
+	minOverlap := options.MinOverlap
+	if minOverlap <= 0 {
+		minOverlap = 0.5
+	}
+	counts := make([]ZoneCount, len(zones))
+	for i, zone := range zones {
+		counts[i] = ZoneCount{Zone: zone.Name, Detectors: make(map[string]int)}
+	}
+	for _, detector := range response.Detectors {
+		for _, object := range detector.Objects {
+			if object.Score < options.MinScore {
+				continue
+			}
+			center := Point{
+				X: float64(object.Rect.Left) + float64(object.Rect.Width)/2,
+				Y: float64(object.Rect.Top) + float64(object.Rect.Height)/2,
+			}
+			for i, zone := range zones {
+				var inside bool
+				switch options.Mode {
+				case ZoneOverlap:
+					inside = zone.overlap(object.Rect) >= minOverlap
+				default:
+					inside = zone.contains(center)
+				}
+				if inside {
+					counts[i].Total++
+					counts[i].Detectors[detector.ID]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// ZoneCounter counts objects in zones across a sequence of frames.
+type ZoneCounter struct {
+	zones   []Zone
+	options *ZoneOptions
+	frames  int
+	peaks   []ZoneCount
+	totals  []ZoneCount
+}
+
+// ZoneSummary describes the objects in a zone across all the frames
+// added to a ZoneCounter.
+type ZoneSummary struct {
+	// Zone is the name of the zone.
+	Zone string `json:"zone"`
+	// Frames is the number of frames.
+	Frames int `json:"frames"`
+	// Peak is the highest number of objects in the zone in any frame.
+	// The peak for each detector may come from a different frame.
+	Peak ZoneCount `json:"peak"`
+	// Mean is the mean number of objects in the zone per frame.
+	Mean float64 `json:"mean"`
+	// MeanDetectors is the mean number of objects in the zone per
+	// frame for each detector.
+	MeanDetectors map[string]float64 `json:"mean_detectors"`
+}
+
+// NewZoneCounter makes a new ZoneCounter for the zones.
+func NewZoneCounter(zones []Zone, options *ZoneOptions) *ZoneCounter {
+	z := &ZoneCounter{
+		zones:   zones,
+		options: options,
+		peaks:   make([]ZoneCount, len(zones)),
+		totals:  make([]ZoneCount, len(zones)),
+	}
+	for i, zone := range zones {
+		z.peaks[i] = ZoneCount{Zone: zone.Name, Detectors: make(map[string]int)}
+		z.totals[i] = ZoneCount{Zone: zone.Name, Detectors: make(map[string]int)}
+	}
+	return z
+}
+
+// Add counts the objects in the next frame, and returns the counts.
+// ZoneCounter is not safe for concurrent use.
+func (z *ZoneCounter) Add(response CheckResponse) []ZoneCount {
+	counts := CountZones(z.zones, response, z.options)
+	z.frames++
+	for i, count := range counts {
+		if count.Total > z.peaks[i].Total {
+			z.peaks[i].Total = count.Total
+		}
+		z.totals[i].Total += count.Total
+		for id, n := range count.Detectors {
+			if n > z.peaks[i].Detectors[id] {
+				z.peaks[i].Detectors[id] = n
+			}
+			z.totals[i].Detectors[id] += n
+		}
+	}
+	return counts
+}
+
+// Summary gets the summary of each zone, in the same order as
+// the zones.
+func (z *ZoneCounter) Summary() []ZoneSummary {
+	summaries := make([]ZoneSummary, len(z.zones))
+	for i, zone := range z.zones {
+		summary := ZoneSummary{
+			Zone:          zone.Name,
+			Frames:        z.frames,
+			Peak:          ZoneCount{Zone: zone.Name, Total: z.peaks[i].Total, Detectors: make(map[string]int)},
+			MeanDetectors: make(map[string]float64),
+		}
+		for id, n := range z.peaks[i].Detectors {
+			summary.Peak.Detectors[id] = n
+		}
+		if z.frames > 0 {
+			summary.Mean = float64(z.totals[i].Total) / float64(z.frames)
+			for id, n := range z.totals[i].Detectors {
+				summary.MeanDetectors[id] = float64(n) / float64(z.frames)
+			}
+		}
+		summaries[i] = summary
+	}
+	return summaries
+}
+
+// StreamZones counts the objects in each zone for every response
+// received from responses, sending the counts on the returned
+// channel. The channel is closed when responses is closed or the
+// context is cancelled.
+func StreamZones(ctx context.Context, zones []Zone, responses <-chan CheckResponse, options *ZoneOptions) <-chan []ZoneCount {
+	counts := make(chan []ZoneCount)
+	go func() {
+		defer close(counts)
+		for {
+			select {
+			case response, ok := <-responses:
+				if !ok {
+					return
+				}
+				select {
+				case counts <- CountZones(zones, response, options):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return counts
+}
@@ -0,0 +1,138 @@
+package objectbox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/matryer/is"
+)
+
+var testZones = []objectbox.Zone{
+	{
+		Name: "door",
+		Polygon: []objectbox.Point{
+			{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100},
+		},
+	},
+	{
+		// a triangle with its right angle at the bottom left
+		Name: "aisle",
+		Polygon: []objectbox.Point{
+			{X: 100, Y: 0}, {X: 300, Y: 200}, {X: 100, Y: 200},
+		},
+	},
+}
+
+func zoneResponse(people, cars []objectbox.Rect) objectbox.CheckResponse {
+	response := objectbox.CheckResponse{
+		Detectors: []objectbox.CheckDetectorResponse{
+			{ID: "people"},
+			{ID: "cars"},
+		},
+	}
+	for _, rect := range people {
+		response.Detectors[0].Objects = append(response.Detectors[0].Objects, objectbox.Object{Rect: rect, Score: 0.9})
+	}
+	for _, rect := range cars {
+		response.Detectors[1].Objects = append(response.Detectors[1].Objects, objectbox.Object{Rect: rect, Score: 0.4})
+	}
+	return response
+}
+
+func TestCountZonesCentroid(t *testing.T) {
+	is := is.New(t)
+	response := zoneResponse([]objectbox.Rect{
+		{Left: 10, Top: 10, Width: 20, Height: 20},   // door
+		{Left: 60, Top: 60, Width: 20, Height: 20},   // door
+		{Left: 120, Top: 150, Width: 20, Height: 20}, // aisle
+		{Left: 250, Top: 10, Width: 20, Height: 20},  // outside the aisle triangle
+	}, []objectbox.Rect{
+		{Left: 80, Top: 80, Width: 60, Height: 60}, // center at 110,110 is in the aisle
+	})
+	counts := objectbox.CountZones(testZones, response, nil)
+	is.Equal(len(counts), 2)
+	is.Equal(counts[0].Zone, "door")
+	is.Equal(counts[0].Total, 2)
+	is.Equal(counts[0].Detectors, map[string]int{"people": 2})
+	is.Equal(counts[1].Zone, "aisle")
+	is.Equal(counts[1].Total, 2)
+	is.Equal(counts[1].Detectors, map[string]int{"people": 1, "cars": 1})
+
+	counts = objectbox.CountZones(testZones, response, &objectbox.ZoneOptions{MinScore: 0.5})
+	is.Equal(counts[1].Detectors, map[string]int{"people": 1})
+}
+
+func TestCountZonesOverlap(t *testing.T) {
+	is := is.New(t)
+	zones := []objectbox.Zone{
+		testZones[0],
+		{
+			// the bottom left half of the door
+			Name: "diagonal",
+			Polygon: []objectbox.Point{
+				{X: 0, Y: 0}, {X: 100, Y: 100}, {X: 0, Y: 100},
+			},
+		},
+	}
+	response := zoneResponse([]objectbox.Rect{
+		{Left: 90, Top: 10, Width: 20, Height: 20}, // half inside the door
+		{Left: 95, Top: 50, Width: 20, Height: 20}, // a quarter inside the door
+		{Left: 50, Top: 0, Width: 50, Height: 50},  // top right of the door
+	}, []objectbox.Rect{
+		{Left: 0, Top: 0, Width: 100, Height: 100}, // the whole door
+	})
+	counts := objectbox.CountZones(zones, response, &objectbox.ZoneOptions{
+		Mode: objectbox.ZoneOverlap,
+	})
+	is.Equal(counts[0].Total, 3)
+	is.Equal(counts[0].Detectors, map[string]int{"people": 2, "cars": 1})
+	is.Equal(counts[1].Total, 1)
+	is.Equal(counts[1].Detectors, map[string]int{"cars": 1})
+
+	counts = objectbox.CountZones(zones, response, &objectbox.ZoneOptions{
+		Mode:       objectbox.ZoneOverlap,
+		MinOverlap: 0.2,
+	})
+	is.Equal(counts[0].Total, 4)
+	is.Equal(counts[1].Total, 1)
+}
+
+func TestZoneCounter(t *testing.T) {
+	is := is.New(t)
+	counter := objectbox.NewZoneCounter(testZones, nil)
+	person := objectbox.Rect{Left: 10, Top: 10, Width: 20, Height: 20}
+	car := objectbox.Rect{Left: 20, Top: 20, Width: 20, Height: 20}
+	counts := counter.Add(zoneResponse([]objectbox.Rect{person, person, person}, nil))
+	is.Equal(counts[0].Total, 3)
+	counter.Add(zoneResponse([]objectbox.Rect{person}, []objectbox.Rect{car, car}))
+	counter.Add(zoneResponse(nil, nil))
+	summary := counter.Summary()
+	is.Equal(len(summary), 2)
+	is.Equal(summary[0].Zone, "door")
+	is.Equal(summary[0].Frames, 3)
+	is.Equal(summary[0].Peak.Total, 3)
+	is.Equal(summary[0].Peak.Detectors, map[string]int{"people": 3, "cars": 2})
+	is.Equal(summary[0].Mean, 2.0)
+	is.Equal(summary[0].MeanDetectors["people"], 4.0/3)
+	is.Equal(summary[1].Peak.Total, 0)
+	is.Equal(summary[1].Mean, 0.0)
+}
+
+func TestStreamZones(t *testing.T) {
+	is := is.New(t)
+	responses := make(chan objectbox.CheckResponse)
+	counts := objectbox.StreamZones(context.Background(), testZones, responses, nil)
+	go func() {
+		defer close(responses)
+		responses <- zoneResponse([]objectbox.Rect{{Left: 10, Top: 10, Width: 20, Height: 20}}, nil)
+		responses <- zoneResponse(nil, nil)
+	}()
+	var frames [][]objectbox.ZoneCount
+	for c := range counts {
+		frames = append(frames, c)
+	}
+	is.Equal(len(frames), 2)
+	is.Equal(frames[0][0].Total, 1)
+	is.Equal(frames[1][0].Total, 0)
+}
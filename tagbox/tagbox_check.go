@@ -14,6 +14,12 @@ import (
 
 // Check gets the tags for the image data provided.
 func (c *Client) Check(image io.Reader) (CheckResponse, error) {
+	return c.CheckWithOptions(image, nil)
+}
+
+// CheckWithOptions gets the tags for the image data provided,
+// using the CheckOptions to control the results.
+func (c *Client) CheckWithOptions(image io.Reader, options *CheckOptions) (CheckResponse, error) {
 	var buf bytes.Buffer
 	w := multipart.NewWriter(&buf)
 	fw, err := w.CreateFormFile("file", "image.dat")
@@ -45,11 +51,17 @@ func (c *Client) Check(image io.Reader) (CheckResponse, error) {
 	if err != nil {
 		return CheckResponse{}, err
 	}
-	return checkResponse, nil
+	return options.filter(checkResponse), nil
 }
 
 // CheckURL gets the tags for the image at the specified URL.
 func (c *Client) CheckURL(imageURL *url.URL) (CheckResponse, error) {
+	return c.CheckURLWithOptions(imageURL, nil)
+}
+
+// CheckURLWithOptions gets the tags for the image at the specified URL,
+// using the CheckOptions to control the results.
+func (c *Client) CheckURLWithOptions(imageURL *url.URL, options *CheckOptions) (CheckResponse, error) {
 	u, err := url.Parse(c.addr + "/tagbox/check")
 	if err != nil {
 		return CheckResponse{}, err
@@ -73,11 +85,17 @@ func (c *Client) CheckURL(imageURL *url.URL) (CheckResponse, error) {
 	if err != nil {
 		return CheckResponse{}, err
 	}
-	return checkResponse, nil
+	return options.filter(checkResponse), nil
 }
 
 // CheckBase64 gets the tags for the image in the encoded Base64 data string.
 func (c *Client) CheckBase64(data string) (CheckResponse, error) {
+	return c.CheckBase64WithOptions(data, nil)
+}
+
+// CheckBase64WithOptions gets the tags for the image in the encoded Base64 data string,
+// using the CheckOptions to control the results.
+func (c *Client) CheckBase64WithOptions(data string, options *CheckOptions) (CheckResponse, error) {
 	u, err := url.Parse(c.addr + "/tagbox/check")
 	if err != nil {
 		return CheckResponse{}, err
@@ -98,5 +116,5 @@ func (c *Client) CheckBase64(data string) (CheckResponse, error) {
 	if err != nil {
 		return CheckResponse{}, err
 	}
-	return checkResponse, nil
+	return options.filter(checkResponse), nil
 }
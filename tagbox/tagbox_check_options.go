@@ -0,0 +1,98 @@
+package tagbox
+
+import (
+	"sort"
+	"strings"
+)
+
+// CheckOptions are additional options that control the results
+// of the Check methods.
+// Tagbox returns every tag, so the options are applied by the client.
+type CheckOptions struct {
+	minConfidence float64
+	maxTags       int
+	customOnly    bool
+	allow         map[string]bool
+	deny          map[string]bool
+}
+
+// NewCheckOptions makes a new CheckOptions object.
+func NewCheckOptions() *CheckOptions {
+	return &CheckOptions{
+		allow: make(map[string]bool),
+		deny:  make(map[string]bool),
+	}
+}
+
+// MinConfidence sets the minimum confidence of tags in the results.
+func (o *CheckOptions) MinConfidence(v float64) {
+	o.minConfidence = v
+}
+
+// MaxTags sets the maximum number of tags, and of custom tags,
+// in the results. The most confident tags are kept.
+func (o *CheckOptions) MaxTags(n int) {
+	o.maxTags = n
+}
+
+// CustomOnly includes only custom tags in the results.
+func (o *CheckOptions) CustomOnly() {
+	o.customOnly = true
+}
+
+// Allow includes only the specified tags in the results.
+// Tags are compared case insensitively. Allow may be called
+// many times.
+func (o *CheckOptions) Allow(tags ...string) {
+	for _, tag := range tags {
+		o.allow[strings.ToLower(tag)] = true
+	}
+}
+
+// Deny excludes the specified tags from the results.
+// Tags are compared case insensitively. Deny may be called
+// many times.
+func (o *CheckOptions) Deny(tags ...string) {
+	for _, tag := range tags {
+		o.deny[strings.ToLower(tag)] = true
+	}
+}
+
+// filter applies the options to the response.
+// If o is nil, the response is returned unchanged.
+func (o *CheckOptions) filter(response CheckResponse) CheckResponse {
+	if o == nil {
+		return response
+	}
+	if o.customOnly {
+		response.Tags = []Tag{}
+	} else {
+		response.Tags = o.filterTags(response.Tags)
+	}
+	response.CustomTags = o.filterTags(response.CustomTags)
+	return response
+}
+
+func (o *CheckOptions) filterTags(tags []Tag) []Tag {
+	filtered := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		if tag.Confidence < o.minConfidence {
+			continue
+		}
+		key := strings.ToLower(tag.Tag)
+		if len(o.allow) > 0 && !o.allow[key] {
+			continue
+		}
+		if o.deny[key] {
+			continue
+		}
+		filtered = append(filtered, tag)
+	}
+	if o.maxTags > 0 && len(filtered) > o.maxTags {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Confidence > filtered[j].Confidence
+		})
+		filtered = filtered[:o.maxTags]
+	}
+	return filtered
+}
@@ -0,0 +1,92 @@
+package tagbox_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+const checkOptionsResponse = `{
+	"success": true,
+	"tags": [
+		{"tag":"one", "confidence":0.7},
+		{"tag":"two", "confidence":0.9},
+		{"tag":"three", "confidence":0.5},
+		{"tag":"Four", "confidence":0.8}
+	],
+	"custom_tags": [
+		{"tag": "monkeys","confidence": 0.58,"id": "monkeys2.jpg"},
+		{"tag": "bonobos","confidence": 0.4,"id": "monkeys3.jpg"}
+	]
+}`
+
+func TestCheckWithOptions(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+
+	options := tagbox.NewCheckOptions()
+	options.MinConfidence(0.55)
+	options.MaxTags(2)
+	res, err := tb.CheckWithOptions(strings.NewReader(`(pretend this is image data)`), options)
+	is.NoErr(err)
+	is.Equal(len(res.Tags), 2)
+	is.Equal(res.Tags[0].Tag, "two")
+	is.Equal(res.Tags[1].Tag, "Four")
+	is.Equal(len(res.CustomTags), 1)
+	is.Equal(res.CustomTags[0].Tag, "monkeys")
+
+	options = tagbox.NewCheckOptions()
+	options.CustomOnly()
+	res, err = tb.CheckWithOptions(strings.NewReader(`(pretend this is image data)`), options)
+	is.NoErr(err)
+	is.Equal(len(res.Tags), 0)
+	is.Equal(len(res.CustomTags), 2)
+}
+
+func TestCheckURLWithOptions(t *testing.T) {
+	is := is.New(t)
+	imageURL, err := url.Parse("https://test.machinebox.io/image1.png")
+	is.NoErr(err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		is.Equal(r.FormValue("url"), imageURL.String())
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	options := tagbox.NewCheckOptions()
+	options.Allow("four", "one", "monkeys")
+	options.Deny("ONE")
+	res, err := tb.CheckURLWithOptions(imageURL, options)
+	is.NoErr(err)
+	is.Equal(len(res.Tags), 1)
+	is.Equal(res.Tags[0].Tag, "Four")
+	is.Equal(len(res.CustomTags), 1)
+	is.Equal(res.CustomTags[0].Tag, "monkeys")
+}
+
+func TestCheckBase64WithOptions(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		is.Equal(r.FormValue("base64"), "base64Str")
+		io.WriteString(w, checkOptionsResponse)
+	}))
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	res, err := tb.CheckBase64WithOptions("base64Str", nil)
+	is.NoErr(err)
+	is.Equal(len(res.Tags), 4)
+	is.Equal(len(res.CustomTags), 2)
+}
@@ -0,0 +1,241 @@
+package tagbox
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DedupeOptions control the behaviour of Dedupe.
+type DedupeOptions struct {
+	// Concurrency is the maximum number of SimilarID requests that
+	// will be made at the same time. Defaults to 4.
+	Concurrency int
+	// Threshold is the confidence at or above which two images
+	// are considered duplicates. Defaults to 0.95.
+	Threshold float64
+	// Keep chooses which ID in a group should be kept.
+	// By default, the ID most similar to the others is kept.
+	Keep func(group DuplicateGroup) string
+	// Remove removes every image in each group except the one
+	// that is kept. Nothing is removed if Keep chooses an ID that
+	// is not in the group.
+	Remove bool
+}
+
+// DedupeReport describes the duplicate images found by Dedupe.
+type DedupeReport struct {
+	// Groups are the groups of duplicate images.
+	Groups []DuplicateGroup `json:"groups"`
+	// Removed are the IDs that were removed.
+	Removed []string `json:"removed,omitempty"`
+	// Errors are the IDs that could not be checked or removed.
+	Errors []DedupeError `json:"errors,omitempty"`
+}
+
+// DuplicateGroup is a group of duplicate and near-duplicate images.
+type DuplicateGroup struct {
+	// Keep is the ID suggested to be kept.
+	Keep string `json:"keep"`
+	// IDs are all the IDs in the group, including Keep.
+	IDs []string `json:"ids"`
+	// Pairs are the similarities that connect the group.
+	Pairs []DuplicatePair `json:"pairs"`
+}
+
+// DuplicatePair describes two similar images.
+type DuplicatePair struct {
+	ID1        string  `json:"id1"`
+	ID2        string  `json:"id2"`
+	Confidence float64 `json:"confidence"`
+}
+
+// DedupeError describes an ID that could not be checked or removed.
+type DedupeError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// Dedupe finds groups of duplicate and near-duplicate images by calling
+// SimilarID for each of the ids, and connecting images that are at
+// least DedupeOptions.Threshold similar. Similar images that are not
+// in ids are included in the groups.
+// Tagbox cannot list the images it has been taught, so the ids
+// must be provided.
+func (c *Client) Dedupe(ctx context.Context, ids []string, options *DedupeOptions) (*DedupeReport, error) {
+	if options == nil {
+		options = &DedupeOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = 0.95
+	}
+	keep := options.Keep
+	if keep == nil {
+		keep = mostConnected
+	}
+	similars := make([][]Tag, len(ids))
+	errs := make([]error, len(ids))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				similars[i], errs[i] = c.SimilarID(ids[i])
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &DedupeReport{}
+	parents := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		parent, ok := parents[id]
+		if !ok || parent == id {
+			parents[id] = id
+			return id
+		}
+		root := find(parent)
+		parents[id] = root
+		return root
+	}
+	seen := make(map[DuplicatePair]bool)
+	var pairs []DuplicatePair
+	for i, id := range ids {
+		if errs[i] != nil {
+			report.Errors = append(report.Errors, DedupeError{
+				ID:    id,
+				Error: errors.Wrap(errs[i], "similar").Error(),
+			})
+			continue
+		}
+		for _, similar := range similars[i] {
+			if similar.ID == id || similar.Confidence < threshold {
+				continue
+			}
+			pair := DuplicatePair{ID1: id, ID2: similar.ID}
+			if pair.ID2 < pair.ID1 {
+				pair.ID1, pair.ID2 = pair.ID2, pair.ID1
+			}
+			if seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			pair.Confidence = similar.Confidence
+			pairs = append(pairs, pair)
+			root1, root2 := find(pair.ID1), find(pair.ID2)
+			if root1 != root2 {
+				parents[root2] = root1
+			}
+		}
+	}
+
+	groups := make(map[string]*DuplicateGroup)
+	for _, pair := range pairs {
+		root := find(pair.ID1)
+		group, ok := groups[root]
+		if !ok {
+			group = &DuplicateGroup{}
+			groups[root] = group
+		}
+		group.Pairs = append(group.Pairs, pair)
+	}
+	for _, group := range groups {
+		members := make(map[string]bool)
+		for _, pair := range group.Pairs {
+			members[pair.ID1] = true
+			members[pair.ID2] = true
+		}
+		for id := range members {
+			group.IDs = append(group.IDs, id)
+		}
+		sort.Strings(group.IDs)
+		group.Keep = keep(*group)
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].IDs[0] < report.Groups[j].IDs[0]
+	})
+	if !options.Remove {
+		return report, nil
+	}
+	// check every group before removing anything, so a bad Keep
+	// cannot remove a whole group
+	for _, group := range report.Groups {
+		if !containsID(group.IDs, group.Keep) {
+			return nil, errors.Errorf("tagbox: Keep chose %q which is not in the group %v", group.Keep, group.IDs)
+		}
+	}
+	for _, group := range report.Groups {
+		for _, id := range group.IDs {
+			if id == group.Keep {
+				continue
+			}
+			if err := c.Remove(id); err != nil {
+				report.Errors = append(report.Errors, DedupeError{
+					ID:    id,
+					Error: errors.Wrap(err, "remove").Error(),
+				})
+				continue
+			}
+			report.Removed = append(report.Removed, id)
+		}
+	}
+	return report, nil
+}
+
+// containsID gets whether id is one of the ids.
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mostConnected gets the ID in the group with the most similar
+// images, preferring the highest total confidence, then the
+// alphabetically first ID.
+func mostConnected(group DuplicateGroup) string {
+	counts := make(map[string]int)
+	totals := make(map[string]float64)
+	for _, pair := range group.Pairs {
+		counts[pair.ID1]++
+		counts[pair.ID2]++
+		totals[pair.ID1] += pair.Confidence
+		totals[pair.ID2] += pair.Confidence
+	}
+	var best string
+	for _, id := range group.IDs {
+		switch {
+		case best == "":
+			best = id
+		case counts[id] > counts[best]:
+			best = id
+		case counts[id] == counts[best] && totals[id] > totals[best]:
+			best = id
+		}
+	}
+	return best
+}
@@ -0,0 +1,134 @@
+package tagbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+func newDedupeServer(is *is.I) (*httptest.Server, func() []string) {
+	similars := map[string][]tagbox.Tag{
+		"a.jpg": {
+			{ID: "b.jpg", Tag: "beach", Confidence: 0.99},
+			{ID: "c.jpg", Tag: "beach", Confidence: 0.90},
+		},
+		"b.jpg": {
+			{ID: "a.jpg", Tag: "beach", Confidence: 0.99},
+			{ID: "c.jpg", Tag: "beach", Confidence: 0.97},
+		},
+		"c.jpg": {
+			{ID: "b.jpg", Tag: "beach", Confidence: 0.97},
+			{ID: "a.jpg", Tag: "beach", Confidence: 0.90},
+		},
+		"d.jpg": {
+			{ID: "e.jpg", Tag: "city", Confidence: 0.96},
+			{ID: "a.jpg", Tag: "beach", Confidence: 0.20},
+		},
+		"f.jpg": {
+			{ID: "a.jpg", Tag: "beach", Confidence: 0.40},
+		},
+	}
+	var lock sync.Mutex
+	var removed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/tagbox/similar":
+			similar, ok := similars[r.FormValue("id")]
+			if !ok {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(struct {
+				Success bool         `json:"success"`
+				Similar []tagbox.Tag `json:"similar"`
+			}{
+				Success: true,
+				Similar: similar,
+			})
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/tagbox/teach/"):
+			lock.Lock()
+			removed = append(removed, strings.TrimPrefix(r.URL.Path, "/tagbox/teach/"))
+			lock.Unlock()
+			w.Write([]byte(`{"success": true}`))
+		default:
+			is.Fail() // unexpected request
+		}
+	}))
+	return srv, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), removed...)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	is := is.New(t)
+	srv, removed := newDedupeServer(is)
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+
+	report, err := tb.Dedupe(context.Background(), []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg", "f.jpg", "missing.jpg"}, nil)
+	is.NoErr(err)
+	is.Equal(len(report.Groups), 2)
+	is.Equal(report.Groups[0].IDs, []string{"a.jpg", "b.jpg", "c.jpg"})
+	is.Equal(report.Groups[0].Keep, "b.jpg") // b.jpg is similar to both the others
+	is.Equal(len(report.Groups[0].Pairs), 2)
+	is.Equal(report.Groups[1].IDs, []string{"d.jpg", "e.jpg"}) // e.jpg was found by SimilarID
+	is.Equal(report.Groups[1].Keep, "d.jpg")
+	is.Equal(len(report.Errors), 1)
+	is.Equal(report.Errors[0].ID, "missing.jpg")
+	is.Equal(len(report.Removed), 0)
+	is.Equal(len(removed()), 0)
+}
+
+func TestDedupeRemove(t *testing.T) {
+	is := is.New(t)
+	srv, removed := newDedupeServer(is)
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+
+	report, err := tb.Dedupe(context.Background(), []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"}, &tagbox.DedupeOptions{
+		Threshold: 0.85,
+		Keep: func(group tagbox.DuplicateGroup) string {
+			return group.IDs[len(group.IDs)-1]
+		},
+		Remove: true,
+	})
+	is.NoErr(err)
+	is.Equal(len(report.Groups), 2)
+	is.Equal(len(report.Groups[0].Pairs), 3)
+	is.Equal(report.Groups[0].Keep, "c.jpg")
+	is.Equal(report.Groups[1].Keep, "e.jpg")
+	is.Equal(report.Removed, []string{"a.jpg", "b.jpg", "d.jpg"})
+	is.Equal(removed(), report.Removed)
+}
+
+func TestDedupeRemoveKeepNotInGroup(t *testing.T) {
+	is := is.New(t)
+	srv, removed := newDedupeServer(is)
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+
+	for _, keep := range []string{"", "z.jpg"} {
+		keep := keep
+		_, err := tb.Dedupe(context.Background(), []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"}, &tagbox.DedupeOptions{
+			Keep: func(group tagbox.DuplicateGroup) string {
+				if group.IDs[0] == "d.jpg" {
+					return keep
+				}
+				return group.IDs[0]
+			},
+			Remove: true,
+		})
+		is.True(err != nil)
+		is.True(strings.Contains(err.Error(), "not in the group"))
+		is.Equal(len(removed()), 0)
+	}
+}
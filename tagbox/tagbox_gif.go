@@ -0,0 +1,159 @@
+package tagbox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/machinebox/sdk-go/internal/gifutil"
+)
+
+// GIFOptions control how the frames of an animated GIF are checked.
+type GIFOptions struct {
+	// Every checks every Nth frame. Defaults to 1.
+	Every int
+	// Interval is the minimum time between checked frames.
+	// If set, Every is ignored.
+	Interval time.Duration
+	// MaxFrames is the maximum number of frames that will be
+	// checked. Defaults to 50.
+	MaxFrames int
+	// Concurrency is the maximum number of frames that will be
+	// checked at the same time. Defaults to 4.
+	Concurrency int
+	// StopTags are the tags, or custom tags, that stop checking once
+	// a frame has one of them with at least Threshold confidence.
+	// Tags are compared case insensitively.
+	StopTags []string
+	// Threshold is the confidence at which StopTags stop checking.
+	Threshold float64
+}
+
+// GIFResult is the outcome of checking an animated GIF.
+type GIFResult struct {
+	// Frames are the tags of the checked frames, in order.
+	Frames []FrameTags `json:"frames"`
+	// TotalFrames is the number of frames in the GIF.
+	TotalFrames int `json:"total_frames"`
+	// Tags are the tags found in any frame, ordered by
+	// Max, highest first.
+	Tags []GIFTag `json:"tags"`
+	// CustomTags are the custom tags found in any frame, ordered
+	// by Max, highest first.
+	CustomTags []GIFTag `json:"custom_tags"`
+	// Stopped is true if checking stopped early because a frame
+	// had one of GIFOptions.StopTags.
+	Stopped bool `json:"stopped"`
+}
+
+// FrameTags are the tags of a single frame.
+type FrameTags struct {
+	// Frame is the index of the frame, starting at 0.
+	Frame int `json:"frame"`
+	// Offset is when the frame is shown.
+	Offset time.Duration `json:"offset"`
+	CheckResponse
+}
+
+// GIFTag is a tag found in the frames of a GIF.
+type GIFTag struct {
+	Tag string `json:"tag"`
+	// Max is the highest confidence of the tag in any frame.
+	Max float64 `json:"max"`
+	// Mean is the mean confidence of the tag across the checked
+	// frames, counting frames without the tag as zero.
+	Mean float64 `json:"mean"`
+	// Frames is the number of frames the tag was found in.
+	Frames int `json:"frames"`
+}
+
+// CheckGIF checks the frames of the animated GIF. Tagbox only sees
+// the first frame of a GIF passed to Check, so CheckGIF decodes the
+// frames and checks them individually.
+// When checking stops early, only the frames checked so far are
+// in the result.
+func (c *Client) CheckGIF(ctx context.Context, image io.Reader, options *GIFOptions) (*GIFResult, error) {
+	if options == nil {
+		options = &GIFOptions{}
+	}
+	stopTags := make(map[string]bool)
+	for _, tag := range options.StopTags {
+		stopTags[strings.ToLower(tag)] = true
+	}
+	result := &GIFResult{}
+	var lock sync.Mutex
+	total, stopped, err := gifutil.Check(ctx, image, gifutil.Options{
+		Every:       options.Every,
+		Interval:    options.Interval,
+		MaxFrames:   options.MaxFrames,
+		Concurrency: options.Concurrency,
+	}, func(frame gifutil.Frame) (bool, error) {
+		response, err := c.Check(bytes.NewReader(frame.Data))
+		if err != nil {
+			return false, err
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		result.Frames = append(result.Frames, FrameTags{
+			Frame:         frame.Index,
+			Offset:        frame.Offset,
+			CheckResponse: response,
+		})
+		for _, tags := range [][]Tag{response.Tags, response.CustomTags} {
+			for _, tag := range tags {
+				if stopTags[strings.ToLower(tag.Tag)] && tag.Confidence >= options.Threshold {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.TotalFrames = total
+	result.Stopped = stopped
+	sort.Slice(result.Frames, func(i, j int) bool {
+		return result.Frames[i].Frame < result.Frames[j].Frame
+	})
+	var tags, customTags [][]Tag
+	for _, frame := range result.Frames {
+		tags = append(tags, frame.Tags)
+		customTags = append(customTags, frame.CustomTags)
+	}
+	result.Tags = aggregateGIFTags(tags)
+	result.CustomTags = aggregateGIFTags(customTags)
+	return result, nil
+}
+
+// aggregateGIFTags combines the tags of each frame.
+func aggregateGIFTags(frames [][]Tag) []GIFTag {
+	index := make(map[string]int)
+	var tags []GIFTag
+	for _, frame := range frames {
+		for _, tag := range frame {
+			i, ok := index[tag.Tag]
+			if !ok {
+				i = len(tags)
+				index[tag.Tag] = i
+				tags = append(tags, GIFTag{Tag: tag.Tag})
+			}
+			if tag.Confidence > tags[i].Max {
+				tags[i].Max = tag.Confidence
+			}
+			tags[i].Mean += tag.Confidence
+			tags[i].Frames++
+		}
+	}
+	for i := range tags {
+		tags[i].Mean /= float64(len(frames))
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Max > tags[j].Max
+	})
+	return tags
+}
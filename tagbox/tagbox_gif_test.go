@@ -0,0 +1,100 @@
+package tagbox_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/matryer/is"
+)
+
+// makeGIF makes an animated GIF with a frame for each color.
+func makeGIF(is *is.I, colors ...color.Color) *bytes.Buffer {
+	g := &gif.GIF{}
+	for _, c := range colors {
+		img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette.Plan9)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(img.Palette.Index(c))
+		}
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 50)
+	}
+	var buf bytes.Buffer
+	is.NoErr(gif.EncodeAll(&buf, g))
+	return &buf
+}
+
+// newGIFServer makes a Tagbox that tags red frames as "fire".
+func newGIFServer(is *is.I) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		img, err := jpeg.Decode(f)
+		is.NoErr(err)
+		red, green, _, _ := img.At(4, 4).RGBA()
+		if red > 0xc000 && green < 0x4000 {
+			io.WriteString(w, `{
+				"success": true,
+				"tags": [{"tag": "fire", "confidence": 0.8}, {"tag": "background", "confidence": 0.4}],
+				"custom_tags": [{"tag": "danger", "confidence": 0.7, "id": "fire.jpg"}]
+			}`)
+			return
+		}
+		io.WriteString(w, `{
+			"success": true,
+			"tags": [{"tag": "background", "confidence": 0.6}]
+		}`)
+	}))
+}
+
+func TestCheckGIF(t *testing.T) {
+	is := is.New(t)
+	srv := newGIFServer(is)
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	red, white := color.RGBA{R: 255, A: 255}, color.White
+	res, err := tb.CheckGIF(context.Background(), makeGIF(is, white, red, white, white), nil)
+	is.NoErr(err)
+	is.Equal(res.TotalFrames, 4)
+	is.Equal(len(res.Frames), 4)
+	is.Equal(res.Frames[1].Tags[0].Tag, "fire")
+	is.Equal(len(res.Tags), 2)
+	is.Equal(res.Tags[0].Tag, "fire")
+	is.Equal(res.Tags[0].Max, 0.8)
+	is.Equal(res.Tags[0].Mean, 0.2)
+	is.Equal(res.Tags[0].Frames, 1)
+	is.Equal(res.Tags[1].Tag, "background")
+	is.Equal(res.Tags[1].Max, 0.6)
+	is.Equal(res.Tags[1].Frames, 4)
+	is.Equal(len(res.CustomTags), 1)
+	is.Equal(res.CustomTags[0].Tag, "danger")
+	is.Equal(res.Stopped, false)
+}
+
+func TestCheckGIFStopTags(t *testing.T) {
+	is := is.New(t)
+	srv := newGIFServer(is)
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	red, white := color.RGBA{R: 255, A: 255}, color.White
+	res, err := tb.CheckGIF(context.Background(), makeGIF(is, white, red, white, white, white), &tagbox.GIFOptions{
+		Concurrency: 1,
+		StopTags:    []string{"Danger"},
+		Threshold:   0.5,
+	})
+	is.NoErr(err)
+	is.Equal(res.Stopped, true)
+	is.Equal(len(res.Frames), 2)
+	is.Equal(res.CustomTags[0].Tag, "danger")
+}
@@ -0,0 +1,318 @@
+// Package taxonomy maps Tagbox tags onto a custom hierarchy of
+// categories.
+//
+// A taxonomy is usually loaded from JSON:
+//
+//	{
+//		"categories": [
+//			{"name": "animal"},
+//			{"name": "dog", "parent": "animal", "synonyms": ["canine", "puppy"]},
+//			{"name": "labrador", "parent": "dog", "synonyms": ["labrador retriever"]}
+//		],
+//		"custom": {
+//			"rex": "labrador"
+//		}
+//	}
+//
+// A "labrador retriever" tag with confidence 0.9 is mapped to
+// labrador (0.9), and aggregated up the hierarchy to dog (0.9) and
+// animal (0.9).
+package taxonomy
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/machinebox/sdk-go/videobox"
+	"github.com/pkg/errors"
+)
+
+// Category is a node in the taxonomy.
+type Category struct {
+	// Name is the name of the category.
+	Name string `json:"name"`
+	// Parent is the name of the parent category, or empty
+	// for top level categories.
+	Parent string `json:"parent,omitempty"`
+	// Synonyms are other tags that mean this category.
+	Synonyms []string `json:"synonyms,omitempty"`
+}
+
+// Definition is the JSON representation of a Taxonomy.
+type Definition struct {
+	// Categories are the categories in the taxonomy.
+	Categories []Category `json:"categories"`
+	// Custom maps custom tags (taught to Tagbox) to category names.
+	Custom map[string]string `json:"custom,omitempty"`
+	// KeepUnmapped includes tags that do not map to any category
+	// in the results, as categories of their own.
+	KeepUnmapped bool `json:"keep_unmapped,omitempty"`
+}
+
+// AggregateFunc combines the confidences of the children of
+// a category.
+type AggregateFunc func(confidences []float64) float64
+
+// Max aggregates confidences by taking the highest.
+func Max(confidences []float64) float64 {
+	var max float64
+	for _, confidence := range confidences {
+		if confidence > max {
+			max = confidence
+		}
+	}
+	return max
+}
+
+// NoisyOr aggregates confidences as the probability that at least one
+// of them is correct, assuming they are independent. For example,
+// labrador (0.5) and poodle (0.5) give dog 0.75.
+func NoisyOr(confidences []float64) float64 {
+	p := 1.0
+	for _, confidence := range confidences {
+		p *= 1 - confidence
+	}
+	return 1 - p
+}
+
+// Taxonomy maps tags onto categories.
+type Taxonomy struct {
+	// Aggregate combines the confidences of the tags in a category and
+	// its children. Defaults to Max.
+	Aggregate AggregateFunc
+
+	keepUnmapped bool
+	parents      map[string]string
+	tags         map[string]string
+	custom       map[string]string
+}
+
+// Result is a category found in the tags.
+type Result struct {
+	// Category is the name of the category.
+	Category string `json:"category"`
+	// Confidence is the aggregated confidence of the category.
+	Confidence float64 `json:"confidence"`
+	// Tags are the original tags that contributed to the category,
+	// including those of its children.
+	Tags []string `json:"tags"`
+}
+
+// Load reads a JSON Definition and makes a Taxonomy.
+func Load(r io.Reader) (*Taxonomy, error) {
+	var def Definition
+	if err := json.NewDecoder(r).Decode(&def); err != nil {
+		return nil, errors.Wrap(err, "decode taxonomy")
+	}
+	return New(def)
+}
+
+// New makes a Taxonomy from the Definition.
+func New(def Definition) (*Taxonomy, error) {
+	t := &Taxonomy{
+		keepUnmapped: def.KeepUnmapped,
+		parents:      make(map[string]string),
+		tags:         make(map[string]string),
+		custom:       make(map[string]string),
+	}
+	for _, category := range def.Categories {
+		if category.Name == "" {
+			return nil, errors.New("category name can not be empty")
+		}
+		if _, ok := t.parents[category.Name]; ok {
+			return nil, errors.Errorf("duplicate category %q", category.Name)
+		}
+		t.parents[category.Name] = category.Parent
+		t.tags[normalize(category.Name)] = category.Name
+	}
+	for _, category := range def.Categories {
+		for _, synonym := range category.Synonyms {
+			if existing, ok := t.tags[normalize(synonym)]; ok && existing != category.Name {
+				return nil, errors.Errorf("%q is a synonym of both %q and %q", synonym, existing, category.Name)
+			}
+			t.tags[normalize(synonym)] = category.Name
+		}
+	}
+	for name, parent := range t.parents {
+		if parent == "" {
+			continue
+		}
+		if _, ok := t.parents[parent]; !ok {
+			return nil, errors.Errorf("category %q has unknown parent %q", name, parent)
+		}
+		seen := map[string]bool{name: true}
+		for p := parent; p != ""; p = t.parents[p] {
+			if seen[p] {
+				return nil, errors.Errorf("category %q is its own ancestor", name)
+			}
+			seen[p] = true
+		}
+	}
+	for tag, category := range def.Custom {
+		if _, ok := t.parents[category]; !ok {
+			return nil, errors.Errorf("custom tag %q maps to unknown category %q", tag, category)
+		}
+		t.custom[normalize(tag)] = category
+	}
+	return t, nil
+}
+
+func normalize(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// category gets the category for the tag, or empty string
+// if it does not map to any category.
+func (t *Taxonomy) category(tag string, custom bool) string {
+	key := normalize(tag)
+	if custom {
+		if category, ok := t.custom[key]; ok {
+			return category
+		}
+	}
+	if category, ok := t.tags[key]; ok {
+		return category
+	}
+	if t.keepUnmapped {
+		return tag
+	}
+	return ""
+}
+
+// ancestry gets the category and all of its ancestors.
+func (t *Taxonomy) ancestry(category string) []string {
+	categories := []string{category}
+	for parent := t.parents[category]; parent != ""; parent = t.parents[parent] {
+		categories = append(categories, parent)
+	}
+	return categories
+}
+
+// Map maps the tags onto categories, aggregating their confidences
+// up the hierarchy. Results are ordered by confidence, highest first.
+func (t *Taxonomy) Map(tags []tagbox.Tag) []Result {
+	return t.mapTags(tags, nil)
+}
+
+// MapCheckResponse maps the tags and custom tags in the response onto
+// categories. Custom tags are mapped using the custom aliases first.
+// See Map for more information.
+func (t *Taxonomy) MapCheckResponse(response tagbox.CheckResponse) []Result {
+	return t.mapTags(response.Tags, response.CustomTags)
+}
+
+func (t *Taxonomy) mapTags(tags, customTags []tagbox.Tag) []Result {
+	aggregate := t.Aggregate
+	if aggregate == nil {
+		aggregate = Max
+	}
+	confidences := make(map[string][]float64)
+	sources := make(map[string][]string)
+	add := func(tag tagbox.Tag, custom bool) {
+		category := t.category(tag.Tag, custom)
+		if category == "" {
+			return
+		}
+		for _, c := range t.ancestry(category) {
+			confidences[c] = append(confidences[c], tag.Confidence)
+			sources[c] = append(sources[c], tag.Tag)
+		}
+	}
+	for _, tag := range tags {
+		add(tag, false)
+	}
+	for _, tag := range customTags {
+		add(tag, true)
+	}
+	results := make([]Result, 0, len(confidences))
+	for category, values := range confidences {
+		results = append(results, Result{
+			Category:   category,
+			Confidence: aggregate(values),
+			Tags:       sources[category],
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Confidence == results[j].Confidence {
+			return results[i].Category < results[j].Category
+		}
+		return results[i].Confidence > results[j].Confidence
+	})
+	return results
+}
+
+// MapVideo maps the tags in the Videobox results onto categories.
+// Each returned Item has the category as its Key, and the instances
+// of all the tags in that category and its children. Instances that
+// overlap or are adjacent are merged into one, and their confidences
+// are combined with Aggregate.
+// Items are ordered by Key.
+func (t *Taxonomy) MapVideo(results *videobox.Tagbox) []videobox.Item {
+	if results == nil {
+		return nil
+	}
+	aggregate := t.Aggregate
+	if aggregate == nil {
+		aggregate = Max
+	}
+	instances := make(map[string][]videobox.Range)
+	for _, item := range results.Tags {
+		category := t.category(item.Key, true)
+		if category == "" {
+			continue
+		}
+		for _, c := range t.ancestry(category) {
+			instances[c] = append(instances[c], item.Instances...)
+		}
+	}
+	items := make([]videobox.Item, 0, len(instances))
+	for category, ranges := range instances {
+		items = append(items, videobox.Item{
+			Key:       category,
+			Instances: mergeRanges(ranges, aggregate),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// mergeRanges merges ranges that overlap or are adjacent, combining
+// their confidences with the aggregate. The merged ranges are ordered
+// by Start.
+func mergeRanges(ranges []videobox.Range, aggregate AggregateFunc) []videobox.Range {
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+	var merged []videobox.Range
+	var confidences []float64
+	flush := func() {
+		if len(confidences) > 0 {
+			merged[len(merged)-1].Confidence = aggregate(confidences)
+		}
+	}
+	for _, r := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if r.Start <= last.End+1 {
+				if r.End > last.End {
+					last.End, last.EndMS = r.End, r.EndMS
+				}
+				if r.StartMS < last.StartMS {
+					last.StartMS = r.StartMS
+				}
+				confidences = append(confidences, r.Confidence)
+				continue
+			}
+		}
+		flush()
+		merged = append(merged, r)
+		confidences = []float64{r.Confidence}
+	}
+	flush()
+	return merged
+}
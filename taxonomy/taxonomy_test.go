@@ -0,0 +1,169 @@
+package taxonomy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/machinebox/sdk-go/taxonomy"
+	"github.com/machinebox/sdk-go/videobox"
+	"github.com/matryer/is"
+)
+
+const definition = `{
+	"categories": [
+		{"name": "animal"},
+		{"name": "dog", "parent": "animal", "synonyms": ["canine", "puppy"]},
+		{"name": "labrador", "parent": "dog", "synonyms": ["Labrador Retriever"]},
+		{"name": "poodle", "parent": "dog"},
+		{"name": "vehicle"}
+	],
+	"custom": {
+		"rex": "labrador"
+	}
+}`
+
+func TestMap(t *testing.T) {
+	is := is.New(t)
+	tx, err := taxonomy.Load(strings.NewReader(definition))
+	is.NoErr(err)
+	results := tx.Map([]tagbox.Tag{
+		{Tag: "labrador retriever", Confidence: 0.5},
+		{Tag: "Poodle", Confidence: 0.5},
+		{Tag: "grass", Confidence: 0.9},
+	})
+	is.Equal(len(results), 4)
+	is.Equal(results[0].Category, "animal")
+	is.Equal(results[0].Confidence, 0.5)
+	is.Equal(results[0].Tags, []string{"labrador retriever", "Poodle"})
+	is.Equal(results[1].Category, "dog")
+	is.Equal(results[2].Category, "labrador")
+	is.Equal(results[3].Category, "poodle")
+
+	tx.Aggregate = taxonomy.NoisyOr
+	results = tx.Map([]tagbox.Tag{
+		{Tag: "labrador retriever", Confidence: 0.5},
+		{Tag: "Poodle", Confidence: 0.5},
+	})
+	is.Equal(results[0].Category, "animal")
+	is.Equal(results[0].Confidence, 0.75)
+	is.Equal(results[1].Category, "dog")
+	is.Equal(results[1].Confidence, 0.75)
+}
+
+func TestMapCheckResponse(t *testing.T) {
+	is := is.New(t)
+	tx, err := taxonomy.Load(strings.NewReader(definition))
+	is.NoErr(err)
+	results := tx.MapCheckResponse(tagbox.CheckResponse{
+		Tags: []tagbox.Tag{
+			{Tag: "puppy", Confidence: 0.6},
+		},
+		CustomTags: []tagbox.Tag{
+			{Tag: "rex", Confidence: 0.8, ID: "rex1.jpg"},
+		},
+	})
+	is.Equal(len(results), 3)
+	is.Equal(results[0].Category, "animal")
+	is.Equal(results[0].Confidence, 0.8)
+	is.Equal(results[1].Category, "dog")
+	is.Equal(results[1].Tags, []string{"puppy", "rex"})
+	is.Equal(results[2].Category, "labrador")
+	is.Equal(results[2].Confidence, 0.8)
+}
+
+func TestMapKeepUnmapped(t *testing.T) {
+	is := is.New(t)
+	tx, err := taxonomy.New(taxonomy.Definition{
+		Categories: []taxonomy.Category{
+			{Name: "dog", Synonyms: []string{"puppy"}},
+		},
+		KeepUnmapped: true,
+	})
+	is.NoErr(err)
+	results := tx.Map([]tagbox.Tag{
+		{Tag: "puppy", Confidence: 0.6},
+		{Tag: "grass", Confidence: 0.9},
+	})
+	is.Equal(len(results), 2)
+	is.Equal(results[0].Category, "grass")
+	is.Equal(results[1].Category, "dog")
+}
+
+func TestMapVideo(t *testing.T) {
+	is := is.New(t)
+	tx, err := taxonomy.Load(strings.NewReader(definition))
+	is.NoErr(err)
+	items := tx.MapVideo(&videobox.Tagbox{
+		Tags: []videobox.Item{
+			{Key: "poodle", Instances: []videobox.Range{{Start: 50, End: 60}}},
+			{Key: "canine", Instances: []videobox.Range{{Start: 10, End: 20}}},
+			{Key: "car", Instances: []videobox.Range{{Start: 0, End: 5}}},
+		},
+	})
+	is.Equal(len(items), 3)
+	is.Equal(items[0].Key, "animal")
+	is.Equal(len(items[0].Instances), 2)
+	is.Equal(items[0].Instances[0].Start, 10)
+	is.Equal(items[0].Instances[1].Start, 50)
+	is.Equal(items[1].Key, "dog")
+	is.Equal(len(items[1].Instances), 2)
+	is.Equal(items[2].Key, "poodle")
+	is.Equal(len(items[2].Instances), 1)
+}
+
+func TestMapVideoOverlapping(t *testing.T) {
+	is := is.New(t)
+	tx, err := taxonomy.Load(strings.NewReader(definition))
+	is.NoErr(err)
+	tx.Aggregate = taxonomy.NoisyOr
+	items := tx.MapVideo(&videobox.Tagbox{
+		Tags: []videobox.Item{
+			{Key: "poodle", Instances: []videobox.Range{
+				{Start: 10, End: 30, StartMS: 1000, EndMS: 3000, Confidence: 0.5},
+				{Start: 80, End: 90, StartMS: 8000, EndMS: 9000, Confidence: 0.6},
+			}},
+			{Key: "canine", Instances: []videobox.Range{
+				{Start: 20, End: 40, StartMS: 2000, EndMS: 4000, Confidence: 0.5},
+				// adjacent to the merged range above
+				{Start: 41, End: 50, StartMS: 4100, EndMS: 5000, Confidence: 0},
+			}},
+		},
+	})
+	is.Equal(len(items), 3)
+	is.Equal(items[1].Key, "dog")
+	is.Equal(items[1].Instances, []videobox.Range{
+		{Start: 10, End: 50, StartMS: 1000, EndMS: 5000, Confidence: 0.75},
+		{Start: 80, End: 90, StartMS: 8000, EndMS: 9000, Confidence: 0.6},
+	})
+	is.Equal(items[2].Key, "poodle")
+	is.Equal(len(items[2].Instances), 2)
+	is.Equal(items[2].Instances[0].Confidence, 0.5)
+}
+
+func TestNewErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := taxonomy.New(taxonomy.Definition{
+		Categories: []taxonomy.Category{{Name: "dog", Parent: "animal"}},
+	})
+	is.Equal(err.Error(), `category "dog" has unknown parent "animal"`)
+	_, err = taxonomy.New(taxonomy.Definition{
+		Categories: []taxonomy.Category{
+			{Name: "a", Parent: "b"},
+			{Name: "b", Parent: "a"},
+		},
+	})
+	is.True(strings.Contains(err.Error(), "is its own ancestor"))
+	_, err = taxonomy.New(taxonomy.Definition{
+		Categories: []taxonomy.Category{
+			{Name: "dog", Synonyms: []string{"pet"}},
+			{Name: "cat", Synonyms: []string{"pet"}},
+		},
+	})
+	is.Equal(err.Error(), `"pet" is a synonym of both "dog" and "cat"`)
+	_, err = taxonomy.New(taxonomy.Definition{
+		Categories: []taxonomy.Category{{Name: "dog"}},
+		Custom:     map[string]string{"rex": "labrador"},
+	})
+	is.Equal(err.Error(), `custom tag "rex" maps to unknown category "labrador"`)
+}
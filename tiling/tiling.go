@@ -0,0 +1,157 @@
+// Package tiling checks very large images by splitting them into
+// overlapping tiles, so small objects are not lost when the box
+// downsizes the image.
+package tiling
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"io"
+	"sync"
+
+	"github.com/machinebox/sdk-go/internal/imageutil"
+	"github.com/pkg/errors"
+)
+
+// Options control how images are tiled and checked.
+type Options struct {
+	// TileSize is the width and height of each tile in pixels.
+	// Defaults to 1024.
+	TileSize int
+	// Overlap is the number of pixels adjacent tiles share, so objects
+	// on the edge of one tile are whole in the next.
+	// Defaults to TileSize/8.
+	Overlap int
+	// Concurrency is the maximum number of tiles that will be
+	// checked at the same time. Defaults to 4.
+	Concurrency int
+	// IncludeFull also checks the whole image, so objects larger
+	// than a tile are found.
+	IncludeFull bool
+	// MergeThreshold is the overlap above which two objects found by
+	// the same detector are considered duplicates, and only the one
+	// with the highest score is kept. Overlap is the area of the
+	// intersection divided by the area of the smaller object, so
+	// objects cut off at the edge of a tile merge with the whole object.
+	// Defaults to 0.5.
+	MergeThreshold float64
+	// Quality is the JPEG quality used to encode tiles.
+	// Defaults to 90.
+	Quality int
+}
+
+func (o *Options) withDefaults() Options {
+	var options Options
+	if o != nil {
+		options = *o
+	}
+	if options.TileSize < 1 {
+		options.TileSize = 1024
+	}
+	if options.Overlap <= 0 {
+		options.Overlap = options.TileSize / 8
+	}
+	if options.Overlap >= options.TileSize {
+		options.Overlap = options.TileSize - 1
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = 4
+	}
+	if options.MergeThreshold <= 0 {
+		options.MergeThreshold = 0.5
+	}
+	if options.Quality < 1 {
+		options.Quality = 90
+	}
+	return options
+}
+
+// Tiles gets the tiles that cover the bounds. Tiles are at most
+// TileSize square, and adjacent tiles overlap by at least Overlap
+// pixels. The last tile in each row and column is aligned with the
+// edge of the bounds.
+func Tiles(bounds image.Rectangle, options *Options) []image.Rectangle {
+	o := options.withDefaults()
+	xs := offsets(bounds.Min.X, bounds.Dx(), o.TileSize, o.Overlap)
+	ys := offsets(bounds.Min.Y, bounds.Dy(), o.TileSize, o.Overlap)
+	tiles := make([]image.Rectangle, 0, len(xs)*len(ys))
+	for _, y := range ys {
+		for _, x := range xs {
+			tile := image.Rect(x, y, x+o.TileSize, y+o.TileSize).Intersect(bounds)
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+// offsets gets the start of each tile along one dimension.
+func offsets(min, length, size, overlap int) []int {
+	if length <= size {
+		return []int{min}
+	}
+	stride := size - overlap
+	var starts []int
+	for start := 0; ; start += stride {
+		if start+size >= length {
+			starts = append(starts, min+length-size)
+			break
+		}
+		starts = append(starts, min+start)
+	}
+	return starts
+}
+
+// checkTiles encodes each tile of the image and calls check with it,
+// concurrently. The first error is returned.
+func checkTiles(ctx context.Context, img image.Image, o Options, check func(tile image.Rectangle, r io.Reader) error) error {
+	tiles := Tiles(img.Bounds(), &o)
+	if o.IncludeFull && len(tiles) > 1 {
+		tiles = append(tiles, img.Bounds())
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	setErr := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	pending := make(chan image.Rectangle)
+	for i := 0; i < o.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tile := range pending {
+				var buf bytes.Buffer
+				if err := jpeg.Encode(&buf, imageutil.SubImage(img, tile), &jpeg.Options{Quality: o.Quality}); err != nil {
+					setErr(errors.Wrapf(err, "encode tile %v", tile))
+					continue
+				}
+				if err := check(tile, &buf); err != nil {
+					setErr(errors.Wrapf(err, "check tile %v", tile))
+				}
+			}
+		}()
+	}
+	for _, tile := range tiles {
+		select {
+		case pending <- tile:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	close(pending)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,154 @@
+package tiling
+
+import (
+	"context"
+	"image"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+)
+
+// CheckObjects checks each tile of the image with Objectbox.
+// The rects of the objects are in the coordinate space of the
+// original image, and duplicate objects found in overlapping tiles
+// are merged using non-maximum suppression.
+// Detectors are in the order they were first seen, and their objects
+// are ordered by score, highest first.
+func CheckObjects(ctx context.Context, client *objectbox.Client, img image.Image, options *Options) (objectbox.CheckResponse, error) {
+	o := options.withDefaults()
+	var (
+		lock      sync.Mutex
+		detectors []objectbox.CheckDetectorResponse
+		indexes   = make(map[string]int)
+	)
+	err := checkTiles(ctx, img, o, func(tile image.Rectangle, r io.Reader) error {
+		res, err := client.Check(r)
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		for _, detector := range res.Detectors {
+			i, ok := indexes[detector.ID]
+			if !ok {
+				i = len(detectors)
+				indexes[detector.ID] = i
+				detectors = append(detectors, objectbox.CheckDetectorResponse{
+					ID:      detector.ID,
+					Name:    detector.Name,
+					Objects: []objectbox.Object{},
+				})
+			}
+			for _, object := range detector.Objects {
+				object.Rect.Left += tile.Min.X
+				object.Rect.Top += tile.Min.Y
+				detectors[i].Objects = append(detectors[i].Objects, object)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return objectbox.CheckResponse{}, err
+	}
+	for i := range detectors {
+		detectors[i].Objects = suppress(detectors[i].Objects, o.MergeThreshold)
+	}
+	return objectbox.CheckResponse{Detectors: detectors}, nil
+}
+
+// suppress performs non-maximum suppression, keeping the highest
+// scoring object from each group of overlapping objects.
+func suppress(objects []objectbox.Object, threshold float64) []objectbox.Object {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].Score > objects[j].Score
+	})
+	kept := make([]objectbox.Object, 0, len(objects))
+	for _, object := range objects {
+		duplicate := false
+		for _, k := range kept {
+			if overlap(object.Rect, k.Rect) > threshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, object)
+		}
+	}
+	return kept
+}
+
+// overlap gets the area of the intersection of a and b, divided by
+// the area of the smaller of the two.
+func overlap(a, b objectbox.Rect) float64 {
+	ra := image.Rect(a.Left, a.Top, a.Left+a.Width, a.Top+a.Height)
+	rb := image.Rect(b.Left, b.Top, b.Left+b.Width, b.Top+b.Height)
+	in := ra.Intersect(rb)
+	if in.Empty() {
+		return 0
+	}
+	smaller := a.Width * a.Height
+	if area := b.Width * b.Height; area < smaller {
+		smaller = area
+	}
+	if smaller == 0 {
+		return 0
+	}
+	return float64(in.Dx()*in.Dy()) / float64(smaller)
+}
+
+// CheckTags checks each tile of the image with Tagbox.
+// The confidence of each tag, and custom tag, is the highest
+// confidence it had in any tile. Tags are ordered by confidence,
+// highest first.
+func CheckTags(ctx context.Context, client *tagbox.Client, img image.Image, options *Options) (tagbox.CheckResponse, error) {
+	o := options.withDefaults()
+	var (
+		lock       sync.Mutex
+		tags       = make(map[string]tagbox.Tag)
+		customTags = make(map[string]tagbox.Tag)
+	)
+	err := checkTiles(ctx, img, o, func(tile image.Rectangle, r io.Reader) error {
+		res, err := client.Check(r)
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		for _, tag := range res.Tags {
+			if existing, ok := tags[tag.Tag]; !ok || tag.Confidence > existing.Confidence {
+				tags[tag.Tag] = tag
+			}
+		}
+		for _, tag := range res.CustomTags {
+			if existing, ok := customTags[tag.Tag]; !ok || tag.Confidence > existing.Confidence {
+				customTags[tag.Tag] = tag
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return tagbox.CheckResponse{}, err
+	}
+	return tagbox.CheckResponse{
+		Tags:       sortedTags(tags),
+		CustomTags: sortedTags(customTags),
+	}, nil
+}
+
+func sortedTags(tags map[string]tagbox.Tag) []tagbox.Tag {
+	sorted := make([]tagbox.Tag, 0, len(tags))
+	for _, tag := range tags {
+		sorted = append(sorted, tag)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Confidence == sorted[j].Confidence {
+			return sorted[i].Tag < sorted[j].Tag
+		}
+		return sorted[i].Confidence > sorted[j].Confidence
+	})
+	return sorted
+}
@@ -0,0 +1,161 @@
+package tiling_test
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/machinebox/sdk-go/objectbox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/machinebox/sdk-go/tiling"
+	"github.com/matryer/is"
+)
+
+// testImage makes a white image with a red square.
+func testImage(square image.Rectangle) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+	draw.Draw(img, square, image.NewUniform(color.RGBA{R: 255, A: 255}), image.ZP, draw.Src)
+	return img
+}
+
+// findRed decodes the uploaded image and finds the bounds of
+// the red pixels in it.
+func findRed(is *is.I, r *http.Request) image.Rectangle {
+	f, _, err := r.FormFile("file")
+	is.NoErr(err)
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	is.NoErr(err)
+	var found image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cr, cg, cb, _ := img.At(x, y).RGBA()
+			if cr > 0xc000 && cg < 0x6000 && cb < 0x6000 {
+				found = found.Union(image.Rect(x-b.Min.X, y-b.Min.Y, x-b.Min.X+1, y-b.Min.Y+1))
+			}
+		}
+	}
+	return found
+}
+
+func TestCheckObjects(t *testing.T) {
+	is := is.New(t)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/objectbox/check")
+		atomic.AddInt32(&requests, 1)
+		detector := objectbox.CheckDetectorResponse{
+			ID:      "red",
+			Name:    "Red squares",
+			Objects: []objectbox.Object{},
+		}
+		if red := findRed(is, r); !red.Empty() {
+			detector.Objects = append(detector.Objects, objectbox.Object{
+				Rect: objectbox.Rect{
+					Left:   red.Min.X,
+					Top:    red.Min.Y,
+					Width:  red.Dx(),
+					Height: red.Dy(),
+				},
+				Score: float64(red.Dx()*red.Dy()) / 400,
+			})
+		}
+		json.NewEncoder(w).Encode(struct {
+			Success bool `json:"success"`
+			objectbox.CheckResponse
+		}{
+			Success: true,
+			CheckResponse: objectbox.CheckResponse{
+				Detectors: []objectbox.CheckDetectorResponse{detector},
+			},
+		})
+	}))
+	defer srv.Close()
+	ob := objectbox.New(srv.URL)
+	img := testImage(image.Rect(200, 40, 220, 60))
+	res, err := tiling.CheckObjects(context.Background(), ob, img, &tiling.Options{
+		TileSize: 128,
+		Overlap:  32,
+	})
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(&requests), int32(6))
+	is.Equal(len(res.Detectors), 1)
+	is.Equal(res.Detectors[0].ID, "red")
+	is.Equal(len(res.Detectors[0].Objects), 1) // duplicates should be merged
+	rect := res.Detectors[0].Objects[0].Rect
+	is.True(rect.Left >= 198 && rect.Left <= 202)
+	is.True(rect.Top >= 38 && rect.Top <= 42)
+	is.True(rect.Width >= 18 && rect.Width <= 22)
+
+	// the square is cut off by the edge of the first row of tiles
+	img = testImage(image.Rect(40, 110, 60, 130))
+	res, err = tiling.CheckObjects(context.Background(), ob, img, &tiling.Options{
+		TileSize:    128,
+		Overlap:     32,
+		IncludeFull: true,
+	})
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(&requests), int32(13))
+	is.Equal(len(res.Detectors[0].Objects), 1)
+	rect = res.Detectors[0].Objects[0].Rect
+	is.True(rect.Height >= 18 && rect.Height <= 22)
+}
+
+func TestCheckTags(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		res := tagbox.CheckResponse{
+			Tags: []tagbox.Tag{
+				{Tag: "white", Confidence: 0.5},
+			},
+		}
+		if red := findRed(is, r); !red.Empty() {
+			res.Tags = append(res.Tags, tagbox.Tag{Tag: "red", Confidence: 0.9})
+			res.CustomTags = append(res.CustomTags, tagbox.Tag{Tag: "square", Confidence: float64(red.Dx()) / 25, ID: "square.jpg"})
+		}
+		json.NewEncoder(w).Encode(struct {
+			Success bool `json:"success"`
+			tagbox.CheckResponse
+		}{
+			Success:       true,
+			CheckResponse: res,
+		})
+	}))
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	img := testImage(image.Rect(40, 110, 60, 130))
+	res, err := tiling.CheckTags(context.Background(), tb, img, &tiling.Options{
+		TileSize: 128,
+		Overlap:  32,
+	})
+	is.NoErr(err)
+	is.Equal(len(res.Tags), 2)
+	is.Equal(res.Tags[0].Tag, "red")
+	is.Equal(res.Tags[0].Confidence, 0.9)
+	is.Equal(res.Tags[1].Tag, "white")
+	is.Equal(len(res.CustomTags), 1)
+	is.Equal(res.CustomTags[0].ID, "square.jpg")
+}
+
+func TestCheckTagsError(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	tb := tagbox.New(srv.URL)
+	_, err := tiling.CheckTags(context.Background(), tb, testImage(image.Rect(0, 0, 1, 1)), &tiling.Options{
+		TileSize: 128,
+	})
+	is.True(err != nil)
+}
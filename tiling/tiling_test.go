@@ -0,0 +1,28 @@
+package tiling_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/machinebox/sdk-go/tiling"
+	"github.com/matryer/is"
+)
+
+func TestTiles(t *testing.T) {
+	is := is.New(t)
+	tiles := tiling.Tiles(image.Rect(0, 0, 300, 200), &tiling.Options{
+		TileSize: 128,
+		Overlap:  32,
+	})
+	is.Equal(tiles, []image.Rectangle{
+		image.Rect(0, 0, 128, 128),
+		image.Rect(96, 0, 224, 128),
+		image.Rect(172, 0, 300, 128),
+		image.Rect(0, 72, 128, 200),
+		image.Rect(96, 72, 224, 200),
+		image.Rect(172, 72, 300, 200),
+	})
+
+	tiles = tiling.Tiles(image.Rect(10, 10, 100, 50), nil)
+	is.Equal(tiles, []image.Rectangle{image.Rect(10, 10, 100, 50)})
+}
@@ -0,0 +1,327 @@
+// Package xmp writes Tagbox and Facebox results to XMP sidecar files,
+// so they appear as keywords and people in photo management tools
+// such as Adobe Lightroom.
+//
+// Tags are written as dc:subject keywords, and faces are written as
+// Metadata Working Group (MWG) face regions. Existing sidecars are
+// merged with, rather than overwritten.
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/pkg/errors"
+)
+
+// Metadata is the metadata stored in a sidecar.
+type Metadata struct {
+	// Keywords are the dc:subject keywords.
+	Keywords []string
+	// Regions are the MWG regions.
+	Regions []Region
+	// Width and Height are the dimensions of the image in pixels
+	// that the regions apply to.
+	Width, Height int
+}
+
+// Region is an area of the image. Coordinates are normalized
+// between 0 and 1, and X and Y are the center of the area,
+// as in the MWG specification.
+type Region struct {
+	// Name is the name of the person.
+	Name string
+	// Type is the type of region. Defaults to "Face".
+	Type string
+	X, Y float64
+	W, H float64
+}
+
+// contains checks whether the point is inside the region.
+func (r Region) contains(x, y float64) bool {
+	return math.Abs(x-r.X) <= r.W/2 && math.Abs(y-r.Y) <= r.H/2
+}
+
+// Keywords gets the tags and custom tags in the response with
+// at least minConfidence, without duplicates.
+func Keywords(response tagbox.CheckResponse, minConfidence float64) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, tags := range [][]tagbox.Tag{response.Tags, response.CustomTags} {
+		for _, tag := range tags {
+			key := strings.ToLower(tag.Tag)
+			if tag.Confidence < minConfidence || seen[key] {
+				continue
+			}
+			seen[key] = true
+			keywords = append(keywords, tag.Tag)
+		}
+	}
+	return keywords
+}
+
+// FaceRegions makes regions from the faces found in an image with
+// the specified dimensions. Faces that were not recognized are only
+// included if includeUnknown is true.
+func FaceRegions(faces []facebox.Face, width, height int, includeUnknown bool) []Region {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	var regions []Region
+	for _, face := range faces {
+		if !face.Matched && !includeUnknown {
+			continue
+		}
+		regions = append(regions, Region{
+			Name: face.Name,
+			Type: "Face",
+			X:    (float64(face.Rect.Left) + float64(face.Rect.Width)/2) / float64(width),
+			Y:    (float64(face.Rect.Top) + float64(face.Rect.Height)/2) / float64(height),
+			W:    float64(face.Rect.Width) / float64(width),
+			H:    float64(face.Rect.Height) / float64(height),
+		})
+	}
+	return regions
+}
+
+// SidecarPath gets the path of the sidecar for the image, which
+// replaces the extension with .xmp.
+func SidecarPath(imagePath string) string {
+	return strings.TrimSuffix(imagePath, filepath.Ext(imagePath)) + ".xmp"
+}
+
+// Read reads the metadata from a sidecar.
+func Read(r io.Reader) (*Metadata, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var metadata Metadata
+	for _, subject := range find(doc.root, nsDC, "subject") {
+		for _, li := range find(subject, nsRDF, "li") {
+			metadata.Keywords = append(metadata.Keywords, text(li))
+		}
+	}
+	for _, regions := range find(doc.root, nsMWGRS, "Regions") {
+		if dims := child(regions, nsMWGRS, "AppliedToDimensions"); dims != nil {
+			metadata.Width, _ = strconv.Atoi(prop(dims, nsStDim, "w"))
+			metadata.Height, _ = strconv.Atoi(prop(dims, nsStDim, "h"))
+		}
+		for _, r := range readRegions(regions) {
+			metadata.Regions = append(metadata.Regions, r.region)
+		}
+	}
+	return &metadata, nil
+}
+
+type regionNode struct {
+	region Region
+	node   *node
+}
+
+// readRegions reads the regions in the mwg-rs:Regions node.
+func readRegions(regions *node) []regionNode {
+	var nodes []regionNode
+	list := child(regions, nsMWGRS, "RegionList")
+	if list == nil {
+		return nil
+	}
+	for _, li := range find(list, nsRDF, "li") {
+		n := li
+		if desc := child(li, nsRDF, "Description"); desc != nil {
+			n = desc
+		}
+		region := Region{
+			Name: prop(n, nsMWGRS, "Name"),
+			Type: prop(n, nsMWGRS, "Type"),
+		}
+		if area := child(n, nsMWGRS, "Area"); area != nil {
+			region.X, _ = strconv.ParseFloat(prop(area, nsStArea, "x"), 64)
+			region.Y, _ = strconv.ParseFloat(prop(area, nsStArea, "y"), 64)
+			region.W, _ = strconv.ParseFloat(prop(area, nsStArea, "w"), 64)
+			region.H, _ = strconv.ParseFloat(prop(area, nsStArea, "h"), 64)
+		}
+		nodes = append(nodes, regionNode{region: region, node: n})
+	}
+	return nodes
+}
+
+// Merge merges the metadata into the existing sidecar, and writes
+// the result to w. If existing is nil, a new sidecar is written.
+// Keywords that are already present (ignoring case) are not added
+// again. A region is not added if its center is inside an existing
+// region; if the existing region has no name, it is given the name
+// of the new region. Everything else in the existing sidecar is kept.
+func Merge(w io.Writer, existing io.Reader, metadata Metadata) error {
+	if existing == nil {
+		existing = strings.NewReader(emptyPacket)
+	}
+	doc, err := parse(existing)
+	if err != nil {
+		return err
+	}
+	rdf := doc.root
+	if !is(rdf, rdf.name, nsRDF, "RDF") {
+		found := find(doc.root, nsRDF, "RDF")
+		if len(found) == 0 {
+			return errors.New("xmp: missing rdf:RDF")
+		}
+		rdf = found[0]
+	}
+	var descriptions []*node
+	for _, n := range elements(rdf) {
+		if is(n, n.name, nsRDF, "Description") {
+			descriptions = append(descriptions, n)
+		}
+	}
+	if len(descriptions) == 0 {
+		desc := appendElement(rdf, nsRDF, "Description")
+		setAttr(desc, nsRDF, "about", "")
+		descriptions = append(descriptions, desc)
+	}
+	if len(metadata.Keywords) > 0 {
+		mergeKeywords(descriptions, metadata.Keywords)
+	}
+	if len(metadata.Regions) > 0 {
+		mergeRegions(descriptions, metadata)
+	}
+	return doc.write(w)
+}
+
+// findFirst gets the first node with the name in any of the
+// descriptions, or nil.
+func findFirst(descriptions []*node, ns, local string) *node {
+	for _, desc := range descriptions {
+		if found := find(desc, ns, local); len(found) > 0 {
+			return found[0]
+		}
+	}
+	return nil
+}
+
+// childOrNew gets the first child of n with the name,
+// adding it if there is none.
+func childOrNew(n *node, ns, local string) *node {
+	if c := child(n, ns, local); c != nil {
+		return c
+	}
+	return appendElement(n, ns, local)
+}
+
+func mergeKeywords(descriptions []*node, keywords []string) {
+	subject := findFirst(descriptions, nsDC, "subject")
+	if subject == nil {
+		subject = appendElement(descriptions[0], nsDC, "subject")
+	}
+	bag := childOrNew(subject, nsRDF, "Bag")
+	existing := make(map[string]bool)
+	for _, li := range find(bag, nsRDF, "li") {
+		existing[strings.ToLower(text(li))] = true
+	}
+	for _, keyword := range keywords {
+		key := strings.ToLower(keyword)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		li := appendElement(bag, nsRDF, "li")
+		li.children = []interface{}{xml.CharData(keyword)}
+	}
+}
+
+func mergeRegions(descriptions []*node, metadata Metadata) {
+	regions := findFirst(descriptions, nsMWGRS, "Regions")
+	if regions == nil {
+		regions = appendElement(descriptions[0], nsMWGRS, "Regions")
+		setAttr(regions, nsRDF, "parseType", "Resource")
+	}
+	if metadata.Width > 0 && metadata.Height > 0 && child(regions, nsMWGRS, "AppliedToDimensions") == nil {
+		dims := appendElement(regions, nsMWGRS, "AppliedToDimensions")
+		setAttr(dims, nsStDim, "w", strconv.Itoa(metadata.Width))
+		setAttr(dims, nsStDim, "h", strconv.Itoa(metadata.Height))
+		setAttr(dims, nsStDim, "unit", "pixel")
+	}
+	existing := readRegions(regions)
+	bag := childOrNew(childOrNew(regions, nsMWGRS, "RegionList"), nsRDF, "Bag")
+	for _, region := range metadata.Regions {
+		duplicate := false
+		for _, e := range existing {
+			if !e.region.contains(region.X, region.Y) {
+				continue
+			}
+			duplicate = true
+			if e.region.Name == "" && region.Name != "" {
+				setProp(e.node, nsMWGRS, "Name", region.Name)
+			}
+			break
+		}
+		if duplicate {
+			continue
+		}
+		if region.Type == "" {
+			region.Type = "Face"
+		}
+		li := appendElement(bag, nsRDF, "li")
+		desc := appendElement(li, nsRDF, "Description")
+		if region.Name != "" {
+			setAttr(desc, nsMWGRS, "Name", region.Name)
+		}
+		setAttr(desc, nsMWGRS, "Type", region.Type)
+		area := appendElement(desc, nsMWGRS, "Area")
+		setAttr(area, nsStArea, "x", formatFloat(region.X))
+		setAttr(area, nsStArea, "y", formatFloat(region.Y))
+		setAttr(area, nsStArea, "w", formatFloat(region.W))
+		setAttr(area, nsStArea, "h", formatFloat(region.H))
+		setAttr(area, nsStArea, "unit", "normalized")
+		existing = append(existing, regionNode{region: region, node: desc})
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
+
+// WriteSidecar merges the metadata into the sidecar for the image,
+// creating it if it does not exist. See Merge.
+func WriteSidecar(imagePath string, metadata Metadata) error {
+	path := SidecarPath(imagePath)
+	var existing io.Reader
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		existing = bytes.NewReader(b)
+	case !os.IsNotExist(err):
+		return err
+	}
+	var buf bytes.Buffer
+	if err := Merge(&buf, existing, metadata); err != nil {
+		return errors.Wrap(err, path)
+	}
+	// write to a temporary file first, so a failure does not
+	// leave a broken sidecar
+	f, err := ioutil.TempFile(filepath.Dir(path), ".xmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := buf.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,192 @@
+package xmp
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/gif"  // register the GIF decoder for image dimensions
+	_ "image/jpeg" // register the JPEG decoder for image dimensions
+	_ "image/png"  // register the PNG decoder for image dimensions
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/pkg/errors"
+)
+
+// ExportOptions control which boxes are used to make sidecars.
+type ExportOptions struct {
+	// Tagbox is the client used to get keywords. If nil, no
+	// keywords are written.
+	Tagbox *tagbox.Client
+	// Facebox is the client used to get face regions. If nil, no
+	// regions are written.
+	Facebox *facebox.Client
+	// MinConfidence is the minimum confidence of tags written
+	// as keywords.
+	MinConfidence float64
+	// IncludeUnknown writes regions for faces that were not
+	// recognized.
+	IncludeUnknown bool
+	// Concurrency is the maximum number of images that will be
+	// checked at the same time by ExportDir. Defaults to 4.
+	Concurrency int
+}
+
+// ExportReport describes the sidecars written by ExportDir.
+type ExportReport struct {
+	// Written are the paths of the images whose sidecars were
+	// written, relative to the directory.
+	Written []string `json:"written"`
+	// Failed are the images whose sidecars could not be written.
+	Failed []ExportError `json:"failed"`
+}
+
+// ExportError describes an image whose sidecar could not be written.
+type ExportError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// exportExts are the file extensions considered to be images
+// by ExportDir.
+var exportExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+}
+
+// Export checks the image with the boxes, and merges the results into
+// its sidecar. See WriteSidecar.
+func Export(imagePath string, options *ExportOptions) error {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+	data, err := ioutil.ReadFile(imagePath)
+	if err != nil {
+		return err
+	}
+	var metadata Metadata
+	if options.Tagbox != nil {
+		response, err := options.Tagbox.Check(bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrap(err, "tagbox")
+		}
+		metadata.Keywords = Keywords(response, options.MinConfidence)
+	}
+	if options.Facebox != nil {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrap(err, "decode image")
+		}
+		faces, err := options.Facebox.Check(bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrap(err, "facebox")
+		}
+		metadata.Width, metadata.Height = config.Width, config.Height
+		metadata.Regions = FaceRegions(faces, config.Width, config.Height, options.IncludeUnknown)
+	}
+	return WriteSidecar(imagePath, metadata)
+}
+
+// ExportDir calls Export for every image in the directory tree.
+// Hidden files and directories are skipped. Images with the same name
+// but different extensions share a sidecar, which gets the results of
+// all of them.
+// ExportDir returns the report along with ctx.Err() if the context
+// is cancelled before all images are exported.
+func ExportDir(ctx context.Context, dir string, options *ExportOptions) (*ExportReport, error) {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != dir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !exportExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk directory")
+	}
+	report := &ExportReport{}
+	var lock sync.Mutex
+	export := func(path string) {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err == nil {
+			err = Export(path, options)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			report.Failed = append(report.Failed, ExportError{Path: rel, Error: err.Error()})
+			return
+		}
+		report.Written = append(report.Written, rel)
+	}
+	// images that share a sidecar (such as a.jpg and a.png) are
+	// exported by the same worker, one after the other, so they do
+	// not overwrite each other's changes
+	var groups [][]string
+	sidecars := make(map[string]int)
+	for _, path := range paths {
+		sidecar := SidecarPath(path)
+		i, ok := sidecars[sidecar]
+		if !ok {
+			i = len(groups)
+			sidecars[sidecar] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], path)
+	}
+	groupsChan := make(chan []string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range groupsChan {
+				for _, path := range group {
+					export(path)
+				}
+			}
+		}()
+	}
+	for _, group := range groups {
+		groupsChan <- group
+	}
+	close(groupsChan)
+	wg.Wait()
+	sort.Strings(report.Written)
+	sort.Slice(report.Failed, func(i, j int) bool {
+		return report.Failed[i].Path < report.Failed[j].Path
+	})
+	return report, ctx.Err()
+}
@@ -0,0 +1,167 @@
+package xmp_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/machinebox/sdk-go/xmp"
+	"github.com/matryer/is"
+)
+
+func writePNG(is *is.I, path string, width, height int) {
+	is.NoErr(os.MkdirAll(filepath.Dir(path), 0755))
+	f, err := os.Create(path)
+	is.NoErr(err)
+	defer f.Close()
+	is.NoErr(png.Encode(f, image.NewGray(image.Rect(0, 0, width, height))))
+}
+
+func TestExportDir(t *testing.T) {
+	is := is.New(t)
+	tagboxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/tagbox/check")
+		io.WriteString(w, `{
+			"success": true,
+			"tags": [
+				{"tag": "beach", "confidence": 0.9},
+				{"tag": "sand", "confidence": 0.1}
+			]
+		}`)
+	}))
+	defer tagboxSrv.Close()
+	faceboxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/facebox/check")
+		io.WriteString(w, `{
+			"success": true,
+			"faces": [
+				{
+					"rect": {"top": 40, "left": 80, "width": 40, "height": 40},
+					"id": "john1.jpg",
+					"name": "John Lennon",
+					"matched": true
+				}
+			]
+		}`)
+	}))
+	defer faceboxSrv.Close()
+
+	dir, err := ioutil.TempDir("", "xmp")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	writePNG(is, filepath.Join(dir, "one.png"), 400, 200)
+	writePNG(is, filepath.Join(dir, "trip", "two.png"), 400, 200)
+	writePNG(is, filepath.Join(dir, ".hidden", "three.png"), 400, 200)
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0644))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "broken.jpg"), []byte("not an image"), 0644))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "trip", "two.xmp"), []byte(existingSidecar), 0644))
+
+	report, err := xmp.ExportDir(context.Background(), dir, &xmp.ExportOptions{
+		Tagbox:        tagbox.New(tagboxSrv.URL),
+		Facebox:       facebox.New(faceboxSrv.URL),
+		MinConfidence: 0.5,
+	})
+	is.NoErr(err)
+	is.Equal(report.Written, []string{"one.png", "trip/two.png"})
+	is.Equal(len(report.Failed), 1)
+	is.Equal(report.Failed[0].Path, "broken.jpg")
+	_, err = os.Stat(filepath.Join(dir, ".hidden", "three.xmp"))
+	is.True(os.IsNotExist(err))
+
+	f, err := os.Open(filepath.Join(dir, "one.xmp"))
+	is.NoErr(err)
+	defer f.Close()
+	metadata, err := xmp.Read(f)
+	is.NoErr(err)
+	is.Equal(metadata.Keywords, []string{"beach"})
+	is.Equal(metadata.Width, 400)
+	is.Equal(metadata.Regions, []xmp.Region{
+		{Name: "John Lennon", Type: "Face", X: 0.25, Y: 0.3, W: 0.1, H: 0.2},
+	})
+
+	f2, err := os.Open(filepath.Join(dir, "trip", "two.xmp"))
+	is.NoErr(err)
+	defer f2.Close()
+	metadata, err = xmp.Read(f2)
+	is.NoErr(err)
+	is.Equal(metadata.Keywords, []string{"Beach", "Holiday & Travel"})
+	is.Equal(len(metadata.Regions), 2)
+}
+
+func TestExportDirSharedSidecar(t *testing.T) {
+	is := is.New(t)
+	var (
+		lock    sync.Mutex
+		waiting = make(map[int]chan struct{})
+	)
+	tagboxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		is.NoErr(err)
+		defer f.Close()
+		config, _, err := image.DecodeConfig(f)
+		is.NoErr(err)
+		// images with the same height share a sidecar; wait a while
+		// for the other one, so that if they are exported at the same
+		// time their sidecars are written at the same time
+		lock.Lock()
+		partner, ok := waiting[config.Height]
+		if ok {
+			close(partner)
+			delete(waiting, config.Height)
+			lock.Unlock()
+		} else {
+			partner = make(chan struct{})
+			waiting[config.Height] = partner
+			lock.Unlock()
+			select {
+			case <-partner:
+			case <-time.After(100 * time.Millisecond):
+				lock.Lock()
+				delete(waiting, config.Height)
+				lock.Unlock()
+			}
+		}
+		// tag each image with its width, so the sidecar shows which
+		// images were merged into it
+		fmt.Fprintf(w, `{"success": true, "tags": [{"tag": "%d", "confidence": 0.9}]}`, config.Width)
+	}))
+	defer tagboxSrv.Close()
+
+	dir, err := ioutil.TempDir("", "xmp")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	for i := 0; i < 5; i++ {
+		// n.png and n.jpg both use n.xmp
+		writePNG(is, filepath.Join(dir, fmt.Sprintf("%d.png", i)), 100, 10+i)
+		writePNG(is, filepath.Join(dir, fmt.Sprintf("%d.jpg", i)), 200, 10+i)
+	}
+	report, err := xmp.ExportDir(context.Background(), dir, &xmp.ExportOptions{
+		Tagbox:      tagbox.New(tagboxSrv.URL),
+		Concurrency: 10,
+	})
+	is.NoErr(err)
+	is.Equal(len(report.Written), 10)
+	is.Equal(len(report.Failed), 0)
+	for i := 0; i < 5; i++ {
+		b, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf("%d.xmp", i)))
+		is.NoErr(err)
+		metadata, err := xmp.Read(bytes.NewReader(b))
+		is.NoErr(err)
+		sort.Strings(metadata.Keywords)
+		is.Equal(metadata.Keywords, []string{"100", "200"})
+	}
+}
@@ -0,0 +1,153 @@
+package xmp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/machinebox/sdk-go/facebox"
+	"github.com/machinebox/sdk-go/tagbox"
+	"github.com/machinebox/sdk-go/xmp"
+	"github.com/matryer/is"
+)
+
+const existingSidecar = `<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="Adobe XMP Core 5.6-c140">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:mwg-rs="http://www.metadataworkinggroup.com/schemas/regions/"
+    xmlns:stDim="http://ns.adobe.com/xap/1.0/sType/Dimensions#"
+    xmlns:stArea="http://ns.adobe.com/xmp/sType/Area#"
+   xmp:Rating="4">
+   <!-- edited by hand -->
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>Beach</rdf:li>
+     <rdf:li>Holiday &amp; Travel</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <mwg-rs:Regions rdf:parseType="Resource">
+    <mwg-rs:AppliedToDimensions stDim:w="400" stDim:h="200" stDim:unit="pixel"/>
+    <mwg-rs:RegionList>
+     <rdf:Bag>
+      <rdf:li>
+       <rdf:Description mwg-rs:Type="Face">
+        <mwg-rs:Area stArea:x="0.25" stArea:y="0.5" stArea:w="0.1" stArea:h="0.2" stArea:unit="normalized"/>
+       </rdf:Description>
+      </rdf:li>
+     </rdf:Bag>
+    </mwg-rs:RegionList>
+   </mwg-rs:Regions>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+func TestKeywords(t *testing.T) {
+	is := is.New(t)
+	keywords := xmp.Keywords(tagbox.CheckResponse{
+		Tags: []tagbox.Tag{
+			{Tag: "beach", Confidence: 0.9},
+			{Tag: "sand", Confidence: 0.2},
+		},
+		CustomTags: []tagbox.Tag{
+			{Tag: "Beach", Confidence: 0.8},
+			{Tag: "Bondi", Confidence: 0.7},
+		},
+	}, 0.5)
+	is.Equal(keywords, []string{"beach", "Bondi"})
+}
+
+func TestFaceRegions(t *testing.T) {
+	is := is.New(t)
+	regions := xmp.FaceRegions([]facebox.Face{
+		{Rect: facebox.Rect{Left: 80, Top: 80, Width: 40, Height: 40}, Name: "John Lennon", Matched: true},
+		{Rect: facebox.Rect{Left: 200, Top: 0, Width: 20, Height: 20}},
+	}, 400, 200, false)
+	is.Equal(len(regions), 1)
+	is.Equal(regions[0], xmp.Region{Name: "John Lennon", Type: "Face", X: 0.25, Y: 0.5, W: 0.1, H: 0.2})
+	regions = xmp.FaceRegions([]facebox.Face{
+		{Rect: facebox.Rect{Left: 200, Top: 0, Width: 20, Height: 20}},
+	}, 400, 200, true)
+	is.Equal(len(regions), 1)
+}
+
+func TestMergeNew(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := xmp.Merge(&buf, nil, xmp.Metadata{
+		Keywords: []string{"beach", "sunset"},
+		Regions: []xmp.Region{
+			{Name: "John Lennon", X: 0.25, Y: 0.5, W: 0.1, H: 0.2},
+		},
+		Width:  400,
+		Height: 200,
+	})
+	is.NoErr(err)
+	is.True(strings.HasPrefix(buf.String(), "<?xpacket begin="))
+	is.True(strings.Contains(buf.String(), `xmlns:dc="http://purl.org/dc/elements/1.1/"`))
+	metadata, err := xmp.Read(&buf)
+	is.NoErr(err)
+	is.Equal(metadata.Keywords, []string{"beach", "sunset"})
+	is.Equal(metadata.Width, 400)
+	is.Equal(metadata.Height, 200)
+	is.Equal(metadata.Regions, []xmp.Region{
+		{Name: "John Lennon", Type: "Face", X: 0.25, Y: 0.5, W: 0.1, H: 0.2},
+	})
+}
+
+func TestMergeExisting(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := xmp.Merge(&buf, strings.NewReader(existingSidecar), xmp.Metadata{
+		Keywords: []string{"beach", "sunset", "<tag>"},
+		Regions: []xmp.Region{
+			{Name: "John Lennon", X: 0.26, Y: 0.51, W: 0.1, H: 0.2},
+			{Name: "Ringo Starr", X: 0.75, Y: 0.5, W: 0.1, H: 0.2},
+		},
+		Width:  4000,
+		Height: 2000,
+	})
+	is.NoErr(err)
+	s := buf.String()
+	is.True(strings.Contains(s, `xmp:Rating="4"`))
+	is.True(strings.Contains(s, `<!-- edited by hand -->`))
+	is.True(strings.Contains(s, `<rdf:li>Holiday &amp; Travel</rdf:li>`))
+	is.True(strings.Contains(s, `<rdf:li>&lt;tag&gt;</rdf:li>`))
+	is.Equal(strings.Count(s, "xmlns:dc="), 1)
+	metadata, err := xmp.Read(strings.NewReader(s))
+	is.NoErr(err)
+	is.Equal(metadata.Keywords, []string{"Beach", "Holiday & Travel", "sunset", "<tag>"})
+	is.Equal(metadata.Width, 400) // existing dimensions are kept
+	is.Equal(len(metadata.Regions), 2)
+	is.Equal(metadata.Regions[0].Name, "John Lennon") // unnamed region is named
+	is.Equal(metadata.Regions[0].X, 0.25)
+	is.Equal(metadata.Regions[1].Name, "Ringo Starr")
+
+	// merging again changes nothing
+	var buf2 bytes.Buffer
+	err = xmp.Merge(&buf2, strings.NewReader(s), xmp.Metadata{
+		Keywords: []string{"beach", "sunset", "<tag>"},
+		Regions: []xmp.Region{
+			{Name: "John Lennon", X: 0.26, Y: 0.51, W: 0.1, H: 0.2},
+			{Name: "Ringo Starr", X: 0.75, Y: 0.5, W: 0.1, H: 0.2},
+		},
+	})
+	is.NoErr(err)
+	is.Equal(buf2.String(), s)
+}
+
+func TestMergeInvalid(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := xmp.Merge(&buf, strings.NewReader(`<x:xmpmeta xmlns:x="adobe:ns:meta/"></x:xmpmeta>`), xmp.Metadata{})
+	is.Equal(err.Error(), "xmp: missing rdf:RDF")
+	err = xmp.Merge(&buf, strings.NewReader(`<x:xmpmeta`), xmp.Metadata{})
+	is.True(err != nil)
+}
+
+func TestSidecarPath(t *testing.T) {
+	is := is.New(t)
+	is.Equal(xmp.SidecarPath("photos/IMG_0001.JPG"), "photos/IMG_0001.xmp")
+	is.Equal(xmp.SidecarPath("photo"), "photo.xmp")
+}
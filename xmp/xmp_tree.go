@@ -0,0 +1,360 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	nsXMLNS  = "xmlns"
+	nsRDF    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC     = "http://purl.org/dc/elements/1.1/"
+	nsMWGRS  = "http://www.metadataworkinggroup.com/schemas/regions/"
+	nsStArea = "http://ns.adobe.com/xmp/sType/Area#"
+	nsStDim  = "http://ns.adobe.com/xap/1.0/sType/Dimensions#"
+)
+
+// defaultPrefixes are the prefixes used when declaring namespaces.
+var defaultPrefixes = map[string]string{
+	nsRDF:    "rdf",
+	nsDC:     "dc",
+	nsMWGRS:  "mwg-rs",
+	nsStArea: "stArea",
+	nsStDim:  "stDim",
+}
+
+// emptyPacket is the XMP packet that new sidecars start from.
+const emptyPacket = "<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n" +
+	"<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n" +
+	" <rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n" +
+	"  <rdf:Description rdf:about=\"\"/>\n" +
+	" </rdf:RDF>\n" +
+	"</x:xmpmeta>\n" +
+	"<?xpacket end=\"w\"?>"
+
+// node is an XML element. Names keep the prefixes used in the
+// document, so content that is not understood is written back
+// exactly as it was read.
+type node struct {
+	name     xml.Name
+	attr     []xml.Attr
+	children []interface{} // *node or xml.Token
+	parent   *node
+}
+
+// document is a parsed XMP packet.
+type document struct {
+	// before and after are the tokens outside the root element,
+	// such as the xpacket processing instructions.
+	before, after []xml.Token
+	root          *node
+}
+
+func parse(r io.Reader) (*document, error) {
+	doc := &document{}
+	d := xml.NewDecoder(r)
+	var current *node
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "xmp: parse")
+		}
+		tok = xml.CopyToken(tok)
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attr: t.Attr, parent: current}
+			if current == nil {
+				if doc.root != nil {
+					return nil, errors.New("xmp: multiple root elements")
+				}
+				doc.root = n
+			} else {
+				current.children = append(current.children, n)
+			}
+			current = n
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("xmp: unexpected end element")
+			}
+			current = current.parent
+		default:
+			switch {
+			case current != nil:
+				current.children = append(current.children, tok)
+			case doc.root == nil:
+				doc.before = append(doc.before, tok)
+			default:
+				doc.after = append(doc.after, tok)
+			}
+		}
+	}
+	if doc.root == nil {
+		return nil, errors.New("xmp: empty document")
+	}
+	if current != nil {
+		return nil, errors.New("xmp: unexpected end of document")
+	}
+	return doc, nil
+}
+
+func (doc *document) write(w io.Writer) error {
+	var buf bytes.Buffer
+	for _, tok := range doc.before {
+		writeToken(&buf, tok)
+	}
+	writeNode(&buf, doc.root)
+	for _, tok := range doc.after {
+		writeToken(&buf, tok)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeNode(buf *bytes.Buffer, n *node) {
+	buf.WriteString("<" + qualified(n.name))
+	for _, a := range n.attr {
+		buf.WriteString(" " + qualified(a.Name) + `="`)
+		escape(buf, a.Value, true)
+		buf.WriteString(`"`)
+	}
+	if len(n.children) == 0 {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteString(">")
+	for _, child := range n.children {
+		switch c := child.(type) {
+		case *node:
+			writeNode(buf, c)
+		case xml.Token:
+			writeToken(buf, c)
+		}
+	}
+	buf.WriteString("</" + qualified(n.name) + ">")
+}
+
+func writeToken(buf *bytes.Buffer, tok xml.Token) {
+	switch t := tok.(type) {
+	case xml.CharData:
+		escape(buf, string(t), false)
+	case xml.Comment:
+		buf.WriteString("<!--")
+		buf.Write(t)
+		buf.WriteString("-->")
+	case xml.ProcInst:
+		buf.WriteString("<?" + t.Target)
+		if len(t.Inst) > 0 {
+			buf.WriteString(" ")
+			buf.Write(t.Inst)
+		}
+		buf.WriteString("?>")
+	case xml.Directive:
+		buf.WriteString("<!")
+		buf.Write(t)
+		buf.WriteString(">")
+	}
+}
+
+// escape writes s escaped for XML. Unlike xml.EscapeText, whitespace
+// in text is written as it is, so existing formatting is kept.
+func escape(buf *bytes.Buffer, s string, attr bool) {
+	for _, r := range s {
+		switch {
+		case r == '&':
+			buf.WriteString("&amp;")
+		case r == '<':
+			buf.WriteString("&lt;")
+		case r == '>':
+			buf.WriteString("&gt;")
+		case r == '"' && attr:
+			buf.WriteString("&quot;")
+		case (r == '\n' || r == '\r' || r == '\t') && attr:
+			buf.WriteString("&#" + strconv.Itoa(int(r)) + ";")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func qualified(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// namespace gets the namespace URI bound to the prefix in the scope of n.
+func namespace(n *node, prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	for ; n != nil; n = n.parent {
+		for _, a := range n.attr {
+			if a.Name.Space == nsXMLNS && a.Name.Local == prefix {
+				return a.Value
+			}
+		}
+	}
+	return ""
+}
+
+// is checks whether the name, used in the scope of n, is local
+// in the namespace.
+func is(n *node, name xml.Name, ns, local string) bool {
+	return name.Local == local && namespace(n, name.Space) == ns
+}
+
+// prefix gets the prefix for the namespace in the scope of n,
+// declaring it if needed. Declarations are made on the outermost
+// rdf:Description, so they are in scope for all its properties.
+func prefix(n *node, ns string) string {
+	for p := n; p != nil; p = p.parent {
+		for _, a := range p.attr {
+			if a.Name.Space == nsXMLNS && a.Value == ns {
+				// make sure the prefix is not redeclared closer to n
+				if namespace(n, a.Name.Local) == ns {
+					return a.Name.Local
+				}
+			}
+		}
+	}
+	declare := n
+	for p := n; p != nil; p = p.parent {
+		if is(p, p.name, nsRDF, "Description") {
+			declare = p
+		}
+	}
+	base := defaultPrefixes[ns]
+	prefix := base
+	for i := 2; namespace(n, prefix) != ""; i++ {
+		prefix = base + strconv.Itoa(i)
+	}
+	declare.attr = append(declare.attr, xml.Attr{
+		Name:  xml.Name{Space: nsXMLNS, Local: prefix},
+		Value: ns,
+	})
+	return prefix
+}
+
+// find gets all the descendants of n with the name, in document order.
+func find(n *node, ns, local string) []*node {
+	var found []*node
+	for _, c := range elements(n) {
+		if is(c, c.name, ns, local) {
+			found = append(found, c)
+		}
+		found = append(found, find(c, ns, local)...)
+	}
+	return found
+}
+
+// child gets the first child of n with the name, or nil.
+func child(n *node, ns, local string) *node {
+	for _, c := range elements(n) {
+		if is(c, c.name, ns, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// elements gets the element children of n.
+func elements(n *node) []*node {
+	var nodes []*node
+	for _, child := range n.children {
+		if c, ok := child.(*node); ok {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// text gets the character data inside n.
+func text(n *node) string {
+	var s strings.Builder
+	for _, child := range n.children {
+		if c, ok := child.(xml.CharData); ok {
+			s.Write(c)
+		}
+	}
+	return strings.TrimSpace(s.String())
+}
+
+// prop gets a property of n, which may be written as an
+// attribute or as a child element.
+func prop(n *node, ns, local string) string {
+	for _, a := range n.attr {
+		if is(n, a.Name, ns, local) {
+			return a.Value
+		}
+	}
+	if c := child(n, ns, local); c != nil {
+		return text(c)
+	}
+	return ""
+}
+
+// setProp sets a property of n, updating the existing attribute
+// or child element if there is one. New properties of an
+// rdf:Description are written as attributes, and others as
+// child elements.
+func setProp(n *node, ns, local, value string) {
+	for i, a := range n.attr {
+		if is(n, a.Name, ns, local) {
+			n.attr[i].Value = value
+			return
+		}
+	}
+	if c := child(n, ns, local); c != nil {
+		c.children = []interface{}{xml.CharData(value)}
+		return
+	}
+	if is(n, n.name, nsRDF, "Description") {
+		setAttr(n, ns, local, value)
+		return
+	}
+	c := appendElement(n, ns, local)
+	c.children = []interface{}{xml.CharData(value)}
+}
+
+// setAttr adds an attribute to n.
+func setAttr(n *node, ns, local, value string) {
+	n.attr = append(n.attr, xml.Attr{
+		Name:  xml.Name{Space: prefix(n, ns), Local: local},
+		Value: value,
+	})
+}
+
+// appendElement makes a new element and appends it to parent,
+// indented to match its depth.
+func appendElement(parent *node, ns, local string) *node {
+	n := &node{
+		name:   xml.Name{Space: prefix(parent, ns), Local: local},
+		parent: parent,
+	}
+	depth := 0
+	for p := parent; p != nil; p = p.parent {
+		depth++
+	}
+	// remove the whitespace before the closing tag of parent,
+	// and add it back after the new element
+	if last := len(parent.children) - 1; last >= 0 {
+		if c, ok := parent.children[last].(xml.CharData); ok && strings.TrimSpace(string(c)) == "" {
+			parent.children = parent.children[:last]
+		}
+	}
+	parent.children = append(parent.children,
+		xml.CharData("\n"+strings.Repeat(" ", depth)),
+		n,
+		xml.CharData("\n"+strings.Repeat(" ", depth-1)),
+	)
+	return n
+}